@@ -0,0 +1,74 @@
+package fourchan
+
+import (
+	"time"
+)
+
+// The activity of a thread over one interval.
+type ActivityBucket struct {
+	// Start of the interval, the end is the start of the next bucket.
+	Start time.Time
+	// Number of posts made during the interval.
+	Posts int
+	// Number of those posts that had a file.
+	Images int
+}
+
+// The most buckets ActivityBuckets makes, about four months of minutes.
+const maxActivityBuckets = 200000
+
+// Count posts and images per interval over the life of the thread.
+// Buckets are aligned the same way as time.Truncate and run from
+// the first post to the last one, including empty intervals in between.
+// Posts without a time are left out. Returns nil when the thread spans more than
+// maxActivityBuckets intervals, use a longer interval for it.
+func (t *Thread) ActivityBuckets(interval time.Duration) []ActivityBucket {
+	if interval <= 0 {
+		return nil
+	}
+
+	var first, last time.Time
+	for i := range t.Posts {
+		if !t.Posts[i].hasTime() {
+			continue
+		}
+		pt := t.Posts[i].PostTime()
+		if first.IsZero() || pt.Before(first) {
+			first = pt
+		}
+		if last.IsZero() || pt.After(last) {
+			last = pt
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	start := first.Truncate(interval)
+	count := last.Sub(start) / interval
+	if count >= maxActivityBuckets {
+		return nil
+	}
+	buckets := make([]ActivityBucket, int(count)+1)
+	for i := range buckets {
+		buckets[i].Start = start.Add(time.Duration(i) * interval)
+	}
+
+	for i := range t.Posts {
+		if !t.Posts[i].hasTime() {
+			continue
+		}
+		bucket := &buckets[int(t.Posts[i].PostTime().Sub(start)/interval)]
+		bucket.Posts++
+		if t.Posts[i].HasFile {
+			bucket.Images++
+		}
+	}
+
+	return buckets
+}
+
+// Does the post have a time at all? Posts without one are dated to the Unix epoch.
+func (p *Post) hasTime() bool {
+	return p.UnixTime != 0 && p.Anomalies&AnomalyNoTime == 0
+}
@@ -0,0 +1,40 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityBuckets(t *testing.T) {
+	thread := &Thread{Posts: []Post{
+		{Meta: Meta{UnixTime: 3605, HasFile: true}},
+		{Meta: Meta{UnixTime: 3650}},
+		{Meta: Meta{UnixTime: 3800, HasFile: true}},
+		{Meta: Meta{UnixTime: 3601}},
+		{Meta: Meta{UnixTime: 0, HasFile: true}},
+	}}
+
+	buckets := thread.ActivityBuckets(time.Minute)
+	expected := []ActivityBucket{
+		{time.Unix(3600, 0), 3, 1},
+		{time.Unix(3660, 0), 0, 0},
+		{time.Unix(3720, 0), 0, 0},
+		{time.Unix(3780, 0), 1, 1},
+	}
+
+	if len(buckets) != len(expected) {
+		t.Fatalf("%d buckets != %d", len(buckets), len(expected))
+	}
+	for i := range expected {
+		if !buckets[i].Start.Equal(expected[i].Start) || buckets[i].Posts != expected[i].Posts || buckets[i].Images != expected[i].Images {
+			t.Fatalf("bucket %d: %+v != %+v", i, buckets[i], expected[i])
+		}
+	}
+
+	if (&Thread{}).ActivityBuckets(time.Minute) != nil {
+		t.Fatal("empty thread has buckets")
+	}
+	if thread.ActivityBuckets(time.Microsecond) != nil {
+		t.Fatal("no limit on the number of buckets")
+	}
+}
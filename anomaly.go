@@ -0,0 +1,73 @@
+package fourchan
+
+import (
+	"strings"
+)
+
+// Stands in for a file size or dimension the API left out or got wrong, rather than a misleading 0.
+const UnknownSize = -1
+
+// The largest width or height 4chan accepts, anything bigger is a broken value.
+const maxFileDimension = 10000
+
+// Something odd about a post as the API sent it, a set of flags.
+type PostAnomaly int
+
+const (
+	// The post has no time, so PostTime is the Unix epoch.
+	AnomalyNoTime PostAnomaly = 1 << iota
+	// The post has a file extension but no renamed file name, so there's no file to fetch and HasFile is false.
+	AnomalyNoFileName
+	// The file size is missing or negative, FileSize is UnknownSize.
+	AnomalyFileSize
+	// The file's width or height is negative or impossibly large, and UnknownSize.
+	AnomalyDimensions
+)
+
+// Describe the anomalies, e.g. "no time, bad file size".
+func (a PostAnomaly) String() string {
+	var names []string
+	for _, flag := range []struct {
+		anomaly PostAnomaly
+		name    string
+	}{
+		{AnomalyNoTime, "no time"},
+		{AnomalyNoFileName, "no file name"},
+		{AnomalyFileSize, "bad file size"},
+		{AnomalyDimensions, "bad dimensions"},
+	} {
+		if a&flag.anomaly != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// Flag and replace the values of a freshly decoded post that can't be right.
+// fileSize is the size as sent, nil when it was missing.
+func (p *Post) checkAnomalies(fileSize *int) {
+	p.Anomalies = 0
+	if p.UnixTime == 0 {
+		p.Anomalies |= AnomalyNoTime
+	}
+	if p.FileExt != "" && p.RenamedFileName == 0 {
+		p.Anomalies |= AnomalyNoFileName
+	}
+	if !p.HasFile || p.FileDeleted {
+		return
+	}
+
+	if fileSize == nil || *fileSize < 0 {
+		p.FileSize = UnknownSize
+		p.Anomalies |= AnomalyFileSize
+	}
+	for _, dimension := range []*int{&p.FileWidth, &p.FileHeight} {
+		if *dimension < 0 || *dimension > maxFileDimension {
+			*dimension = UnknownSize
+			p.Anomalies |= AnomalyDimensions
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPostAnomalies(t *testing.T) {
+	var posts []Post
+	err := json.Unmarshal([]byte(`[
+		{"no": 1, "time": 1500000000, "tim": 100, "ext": ".jpg", "fsize": 2000, "w": 800, "h": 600},
+		{"no": 2, "time": 1500000000, "tim": 101, "ext": ".jpg", "w": 800, "h": 600},
+		{"no": 3, "time": 1500000000, "tim": 102, "ext": ".png", "fsize": -5, "w": -1, "h": 4000000000},
+		{"no": 4, "ext": ".gif", "fsize": 10},
+		{"no": 5, "time": 1500000000, "tim": 103, "ext": ".jpg", "filedeleted": 1},
+		{"no": 6, "time": 1500000000}
+	]`), &posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if posts[0].Anomalies != 0 || posts[0].FileSize != 2000 {
+		t.Fatalf("flagged a good post %+v", posts[0])
+	}
+	if posts[1].Anomalies != AnomalyFileSize || posts[1].FileSize != UnknownSize || posts[1].FileWidth != 800 {
+		t.Fatalf("bad missing size %+v", posts[1])
+	}
+	if posts[2].Anomalies != AnomalyFileSize|AnomalyDimensions || posts[2].FileWidth != UnknownSize || posts[2].FileHeight != UnknownSize {
+		t.Fatalf("bad broken file %+v", posts[2])
+	}
+	if posts[3].Anomalies != AnomalyNoTime|AnomalyNoFileName || posts[3].HasFile {
+		t.Fatalf("bad file without a name %+v", posts[3])
+	}
+	if posts[3].Anomalies.String() != "no time, no file name" || PostAnomaly(0).String() != "none" {
+		t.Fatalf("bad description %q", posts[3].Anomalies)
+	}
+	if posts[4].Anomalies != 0 || posts[5].Anomalies != 0 {
+		t.Fatalf("flagged a deleted file or a post without one %+v %+v", posts[4], posts[5])
+	}
+}
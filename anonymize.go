@@ -0,0 +1,88 @@
+package fourchan
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Returned by an Anonymizer that would hash without a Key.
+// Anyone can compute an unkeyed hash, and poster IDs, names and countries are few enough to try them all.
+var ErrMissingKey = errors.New("anonymizer needs a secret key unless it strips")
+
+// Replaces the details that identify posters before threads are exported or published:
+// poster IDs, names, tripcodes and countries.
+// Pass threads through Thread before handing them to WriteJSONL, WriteEPUB or any other export.
+type Anonymizer struct {
+	// The secret the pseudonyms are derived from. The same key gives the same pseudonyms,
+	// so posts by one poster can still be linked across exports, without the key no one can
+	// tell who they were. Keep it out of the published data.
+	// It is required unless Strip is set, use a long random one.
+	Key []byte
+	// Remove the details instead of replacing them with pseudonyms, names become Anonymous.
+	Strip bool
+	// Leave countries and flags as they are.
+	KeepCountry bool
+}
+
+// A copy of the thread with every post anonymized.
+// Returns ErrMissingKey when there is no Key to hash with.
+func (a *Anonymizer) Thread(t *Thread) (*Thread, error) {
+	if err := a.check(); err != nil {
+		return nil, err
+	}
+	clone := t.Clone()
+	for i := range clone.Posts {
+		a.anonymize(&clone.Posts[i])
+	}
+	return clone, nil
+}
+
+// A copy of the post, anonymized.
+// Returns ErrMissingKey when there is no Key to hash with.
+func (a *Anonymizer) Post(p *Post) (*Post, error) {
+	if err := a.check(); err != nil {
+		return nil, err
+	}
+	clone := p.Clone()
+	a.anonymize(clone)
+	return clone, nil
+}
+
+// Refuse to make pseudonyms without a secret to make them with.
+func (a *Anonymizer) check() error {
+	if !a.Strip && len(a.Key) == 0 {
+		return ErrMissingKey
+	}
+	return nil
+}
+
+func (a *Anonymizer) anonymize(p *Post) {
+	p.AdminId = a.pseudonym("id", p.AdminId)
+	if a.Strip && p.Name != "" {
+		p.Name = "Anonymous"
+	} else if p.Name != "Anonymous" {
+		p.Name = a.pseudonym("name", p.Name)
+	}
+	p.TripCode = a.pseudonym("trip", p.TripCode)
+	if !a.KeepCountry {
+		p.CountryCode = a.pseudonym("country", p.CountryCode)
+		p.TrollCountry = a.pseudonym("troll_country", p.TrollCountry)
+		// The code stands in for the name, which would give the country away.
+		p.Country = ""
+	}
+}
+
+// A keyed hash of the value, or nothing with Strip. Empty values stay empty.
+// The field is part of the hash, so one value gets a different pseudonym in each field.
+func (a *Anonymizer) pseudonym(field, value string) string {
+	if value == "" || a.Strip {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.Key)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
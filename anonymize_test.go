@@ -0,0 +1,48 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestAnonymizer(t *testing.T) {
+	thread := &Thread{Board: "pol", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, AdminId: "Ab1xYz", Name: "moot", TripCode: "!3GqYIJ3Obs", CountryCode: "US", Country: "United States"}},
+		{Meta: Meta{PostNumber: 2, AdminId: "Ab1xYz", Name: "Anonymous", TrollCountry: "PC"}},
+		{Meta: Meta{PostNumber: 3, AdminId: "Qq9ZzZ", Name: "Anonymous"}},
+	}}
+
+	a := &Anonymizer{Key: []byte("secret")}
+	anon, err := a.Thread(thread)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Posts[0].Name != "moot" {
+		t.Fatal("original thread changed")
+	}
+
+	op := anon.Posts[0].Meta
+	if op.Name == "moot" || op.TripCode == "" || op.TripCode == "!3GqYIJ3Obs" || op.Country != "" || len(op.CountryCode) != 16 {
+		t.Fatalf("OP not anonymized %+v", op)
+	}
+	if op.AdminId != anon.Posts[1].AdminId || op.AdminId == anon.Posts[2].AdminId {
+		t.Fatal("poster IDs no longer link posts")
+	}
+	if anon.Posts[1].Name != "Anonymous" || anon.Posts[1].TrollCountry == "PC" || anon.Posts[2].CountryCode != "" {
+		t.Fatalf("bad reply %+v", anon.Posts[1].Meta)
+	}
+
+	if other, _ := (&Anonymizer{Key: []byte("other")}).Post(&thread.Posts[0]); other.AdminId == op.AdminId {
+		t.Fatal("pseudonyms don't depend on the key")
+	}
+	if _, err = (&Anonymizer{}).Thread(thread); err != ErrMissingKey {
+		t.Fatalf("hashed without a key: %v", err)
+	}
+
+	stripped, err := (&Anonymizer{Strip: true, KeepCountry: true}).Post(&thread.Posts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripped.AdminId != "" || stripped.Name != "Anonymous" || stripped.TripCode != "" || stripped.CountryCode != "US" || stripped.Country != "United States" {
+		t.Fatalf("bad stripped post %+v", stripped.Meta)
+	}
+}
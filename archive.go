@@ -0,0 +1,124 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+)
+
+// A third party archive that keeps threads after 4chan prunes them.
+type ArchiveProvider interface {
+	// A short name for the archive, e.g. its host.
+	Name() string
+	// Load a thread from the archive, normalized into the same shape as the live API's.
+	// Returns ThreadNotFoundError when the archive doesn't have the thread.
+	LoadThread(ctx context.Context, board, id string) (*Thread, error)
+}
+
+// An archive that can also find posts without knowing which thread they are in.
+type ArchiveSearcher interface {
+	ArchiveProvider
+	// Every post with a file whose base64 MD5, as the API reports it, is md5.
+	// An empty board searches every board the archive keeps.
+	FindByMD5(ctx context.Context, board, md5 string) ([]PostLocation, error)
+	// The post numbered no and the thread it is in.
+	// Returns PostNotFoundError when the archive doesn't have the post.
+	FindPost(ctx context.Context, board string, no PostID) (PostLocation, error)
+}
+
+// Custom error to indicate no archive has a post.
+type PostNotFoundError struct {
+	Board string
+	Post  PostID
+}
+
+// Name the missing post.
+func (e PostNotFoundError) Error() string {
+	return fmt.Sprintf("Post /%s/%d not found", e.Board, e.Post)
+}
+
+// Look threads that are gone from 4chan up in the given archives, in order.
+// FindPostsByMD5 and FindPost search the archives that are ArchiveSearchers.
+func WithArchiveFallback(archives ...ArchiveProvider) Option {
+	return func(c *Client) {
+		c.archives = archives
+	}
+}
+
+// Try each archive in turn for a thread that is gone.
+// Returns notFound if no archive has the thread, or the last error an archive gave.
+func (c *Client) loadArchivedThread(ctx context.Context, board, id string, notFound error) (*Thread, error) {
+	err := notFound
+	for _, archive := range c.archives {
+		thread, archiveErr := archive.LoadThread(ctx, board, id)
+		if archiveErr == nil {
+			if err = c.hookPosts(thread); err != nil {
+				return nil, err
+			}
+			thread.Board = board
+			QuirksFor(board).fixPosts(thread.Posts)
+			thread.Archive = archive.Name()
+			return thread, nil
+		}
+		if _, missing := archiveErr.(ThreadNotFoundError); !missing {
+			err = archiveErr
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, err
+}
+
+// Search every archive that supports it for posts with the file whose base64 MD5 is md5.
+// Posts found by more than one archive are only listed once. An empty board searches every board.
+// Returns an error only when every archive failed.
+func (c *Client) FindPostsByMD5(ctx context.Context, board, md5 string) ([]PostLocation, error) {
+	var found []PostLocation
+	seen := map[string]bool{}
+	var err error
+	searched := false
+	for _, archive := range c.archives {
+		searcher, ok := archive.(ArchiveSearcher)
+		if !ok {
+			continue
+		}
+		locations, searchErr := searcher.FindByMD5(ctx, board, md5)
+		if searchErr != nil {
+			err = searchErr
+			continue
+		}
+		searched = true
+		for _, location := range locations {
+			key := fmt.Sprintf("%s/%d", location.Board, location.Post.PostNumber)
+			if !seen[key] {
+				seen[key] = true
+				found = append(found, location)
+			}
+		}
+	}
+
+	if !searched && err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// Find which thread a post is in by asking every archive that supports it.
+// Returns PostNotFoundError if none of them have the post.
+func (c *Client) FindPost(ctx context.Context, board string, no PostID) (PostLocation, error) {
+	var err error = PostNotFoundError{board, no}
+	for _, archive := range c.archives {
+		searcher, ok := archive.(ArchiveSearcher)
+		if !ok {
+			continue
+		}
+		location, findErr := searcher.FindPost(ctx, board, no)
+		if findErr == nil {
+			return location, nil
+		}
+		if _, missing := findErr.(PostNotFoundError); !missing {
+			err = findErr
+		}
+	}
+	return PostLocation{}, err
+}
@@ -0,0 +1,56 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// An archive holding a fixed set of threads.
+type fakeArchive map[string]*Thread
+
+func (a fakeArchive) Name() string {
+	return "fake"
+}
+
+func (a fakeArchive) LoadThread(ctx context.Context, board, id string) (*Thread, error) {
+	if thread, ok := a[board+"/"+id]; ok {
+		return thread, nil
+	}
+	return nil, ThreadNotFoundError{board, id}
+}
+
+func TestArchiveFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/g/thread/1.json" {
+			fmt.Fprint(w, `{"posts": [{"no": 1}]}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	archive := fakeArchive{"g/2": {Posts: []Post{{Meta: Meta{PostNumber: 2}}}}}
+	client := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithArchiveFallback(fakeArchive{}, archive))
+
+	live, err := client.LoadThreadById(context.Background(), "g", "1")
+	if err != nil || live.Archive != "" {
+		t.Fatalf("bad live thread %+v %v", live, err)
+	}
+
+	archived, err := client.LoadThreadById(context.Background(), "g", "2")
+	if err != nil || archived.Archive != "fake" || archived.Board != "g" || archived.Posts[0].PostNumber != 2 {
+		t.Fatalf("bad archived thread %+v %v", archived, err)
+	}
+
+	if _, err = client.LoadThreadById(context.Background(), "g", "3"); err != (ThreadNotFoundError{"g", "3"}) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// Without archives a 404 is still reported as such.
+	if _, err = newTestClient(server).LoadThreadById(context.Background(), "g", "2"); err != (ThreadNotFoundError{"g", "2"}) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Load the post numbers of the threads in a board's own archive from archive.json, oldest first.
+// Boards without an archive answer with a ServerError for a 404.
+func LoadArchivedThreadIDs(board string) ([]ThreadID, error) {
+	return defaultClient().LoadArchivedThreadIDs(context.Background(), board)
+}
+
+// Load the post numbers of the threads in a board's own archive from archive.json, oldest first.
+// Boards without an archive answer with a ServerError for a 404, without asking for boards
+// whose BoardQuirks say they have none.
+func (c *Client) LoadArchivedThreadIDs(ctx context.Context, board string) ([]ThreadID, error) {
+	url := fmt.Sprintf("%s/%s/archive.json", c.apiURL, board)
+	if QuirksFor(board).NoArchive {
+		return nil, ServerError{URL: url, StatusCode: http.StatusNotFound}
+	}
+	var ids []ThreadID
+	if _, err := c.getJSON(ctx, url, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Fetch every thread in a board's archive and hand each to ingest, oldest first, see Client.Backfill.
+func Backfill(board string, ingest func(*Thread) error) error {
+	return defaultClient().Backfill(context.Background(), board, ingest)
+}
+
+// Fetch every thread in a board's archive and hand each to ingest, oldest first.
+// Threads that disappear before they are fetched are skipped, and threads that fail to load don't stop
+// the rest, they are listed in the BatchError returned at the end. An error from ingest stops the backfill
+// and is returned as is, as is the context's error once it is done.
+// The client's rate limit applies, so a full archive takes a while.
+func (c *Client) Backfill(ctx context.Context, board string, ingest func(*Thread) error) error {
+	return c.backfill(ctx, board, 0, ingest)
+}
+
+// Backfill a board's archive like Backfill, spreading the threads evenly over d rather than fetching them
+// as fast as the rate limit allows, e.g. to take six hours over a big board and leave room for other requests.
+// The rate limit still applies, so the backfill can take longer than d, never less.
+func (c *Client) BackfillOver(ctx context.Context, board string, d time.Duration, ingest func(*Thread) error) error {
+	return c.backfill(ctx, board, d, ingest)
+}
+
+func (c *Client) backfill(ctx context.Context, board string, d time.Duration, ingest func(*Thread) error) error {
+	ids, err := c.LoadArchivedThreadIDs(ctx, board)
+	if err != nil {
+		return err
+	}
+
+	var failures batchErrors
+	start := time.Now()
+	for i, id := range ids {
+		if d > 0 {
+			if err = sleepUntil(ctx, start.Add(d*time.Duration(i)/time.Duration(len(ids)))); err != nil {
+				return err
+			}
+		}
+		thread, err := c.LoadThread(ctx, board, id)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, gone := err.(ThreadNotFoundError); gone {
+			continue
+		}
+		failures.add(ThreadRef{board, id}.String(), err)
+		if err != nil {
+			continue
+		}
+		if err = ingest(thread); err != nil {
+			return err
+		}
+	}
+
+	return failures.err()
+}
+
+// Wait until t, or until ctx is done, which is the error returned.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,89 @@
+package fourchan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackfill(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/archive.json":
+			fmt.Fprint(w, `[10, 11, 12, 13]`)
+		case "/g/thread/13.json":
+			http.Error(w, "backend down", http.StatusBadGateway)
+		case "/g/thread/10.json":
+			fmt.Fprint(w, `{"posts": [{"no": 10, "archived": 1}]}`)
+		case "/g/thread/12.json":
+			fmt.Fprint(w, `{"posts": [{"no": 12, "archived": 1}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	var ingested []uint64
+	err := Backfill("g", func(thread *Thread) error {
+		if thread.Board != "g" || !thread.Posts[0].Archived {
+			return fmt.Errorf("bad thread %+v", thread)
+		}
+		ingested = append(ingested, thread.Posts[0].PostNumber)
+		return nil
+	})
+	batch, ok := err.(BatchError)
+	if !ok || batch.Total != 3 || len(batch.Failures) != 1 || batch.Failures[0].Item != "/g/13" {
+		t.Fatalf("expected the failed thread to be reported, got %v", err)
+	}
+	if len(ingested) != 2 || ingested[0] != 10 || ingested[1] != 12 {
+		t.Fatalf("bad threads ingested %v", ingested)
+	}
+
+	full := errors.New("disk full")
+	if err = Backfill("g", func(*Thread) error { return full }); err != full {
+		t.Fatalf("ingest error not returned: %v", err)
+	}
+
+	if _, err = LoadArchivedThreadIDs("b"); err == nil {
+		t.Fatal("no error for a board without an archive")
+	}
+}
+
+func TestBackfillOver(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/g/archive.json" {
+			fmt.Fprint(w, `[10, 11, 12, 13]`)
+			return
+		}
+		times = append(times, time.Now())
+		fmt.Fprint(w, `{"posts": [{"no": 1}]}`)
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	err := newTestClient(server).BackfillOver(context.Background(), "g", 200*time.Millisecond, func(*Thread) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(times) != 4 {
+		t.Fatalf("expected 4 threads, got %d", len(times))
+	}
+	// The threads are loaded 50ms apart, the last one 150ms in.
+	if elapsed := times[3].Sub(start); elapsed < 150*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("last thread loaded after %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = newTestClient(server).BackfillOver(ctx, "g", time.Hour, func(*Thread) error { return nil }); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package fourchan
+
+import (
+	"fmt"
+)
+
+// One item of a batch operation that failed.
+type ItemFailure struct {
+	// What failed, e.g. a file name or /g/123 for a thread.
+	Item string
+	Err  error
+}
+
+// Custom error for a batch operation that carried on past failures, listing every one of them.
+type BatchError struct {
+	// How many items the operation tried.
+	Total int
+	// The items that failed, in the order they failed.
+	Failures []ItemFailure
+}
+
+// Count the failures and give the first.
+func (e BatchError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("0 of %d failed", e.Total)
+	}
+	first := e.Failures[0]
+	return fmt.Sprintf("%d of %d failed, the first was %s: %v", len(e.Failures), e.Total, first.Item, first.Err)
+}
+
+// Collects the failures of a batch operation.
+type batchErrors struct {
+	total    int
+	failures []ItemFailure
+}
+
+// Record the outcome of one item.
+func (b *batchErrors) add(item string, err error) {
+	b.total++
+	if err != nil {
+		b.failures = append(b.failures, ItemFailure{item, err})
+	}
+}
+
+// A BatchError if anything failed, otherwise nil.
+func (b *batchErrors) err() error {
+	if len(b.failures) == 0 {
+		return nil
+	}
+	return BatchError{b.total, b.failures}
+}
@@ -0,0 +1,24 @@
+package fourchan
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBatchErrors(t *testing.T) {
+	var b batchErrors
+	b.add("a", nil)
+	if b.err() != nil {
+		t.Fatal("error without failures")
+	}
+	b.add("b", errors.New("broken"))
+	b.add("c", errors.New("also broken"))
+
+	err := b.err()
+	if batch, ok := err.(BatchError); !ok || batch.Total != 3 || len(batch.Failures) != 2 {
+		t.Fatalf("bad error %#v", err)
+	}
+	if err.Error() != "2 of 3 failed, the first was b: broken" {
+		t.Fatal(err)
+	}
+}
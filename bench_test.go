@@ -0,0 +1,114 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A fake API server for benchmarks, serving one thread that gets churn new replies on every request.
+// The oldest replies are dropped to make room, so the thread stays the same size however long a benchmark runs.
+type benchServer struct {
+	mu    sync.Mutex
+	posts []string
+	body  []byte
+	churn int
+	size  int
+	// The number of the next reply.
+	next int
+	// When the thread last grew.
+	modified time.Time
+}
+
+func newBenchServer(size, churn int) *benchServer {
+	s := &benchServer{churn: churn, size: size, next: 1, modified: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s.grow(size)
+	return s
+}
+
+// Add n replies to the thread, dropping the oldest ones past its size but keeping the first post.
+func (s *benchServer) grow(n int) {
+	for i := 0; i < n; i++ {
+		no := s.next
+		s.next++
+		s.posts = append(s.posts, fmt.Sprintf(`{"no": %d, "resto": 1, "time": %d, "name": "Anonymous", "com": "reply %d <a href=\"#p1\" class=\"quotelink\">&gt;&gt;1</a><br><span class=\"quote\">&gt;greentext</span>", "tim": %d, "ext": ".jpg", "md5": "uyJa8uT2H4o1z6xMvUQS1w==", "fsize": 12345, "w": 800, "h": 600}`, no, 1450000000+no, no, 1450000000000+no))
+	}
+	if len(s.posts) > s.size {
+		s.posts = append(s.posts[:1], s.posts[len(s.posts)-s.size+1:]...)
+	}
+	if n > 0 || s.body == nil {
+		s.modified = s.modified.Add(time.Second)
+		s.body = []byte(`{"posts": [` + strings.Join(s.posts, ",") + `]}`)
+	}
+}
+
+func (s *benchServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grow(s.churn)
+	http.ServeContent(w, r, "1.json", s.modified, bytes.NewReader(s.body))
+}
+
+var benchSizes = []int{10, 300, 3000}
+
+func BenchmarkDecodeThread(b *testing.B) {
+	for _, size := range benchSizes {
+		body := newBenchServer(size, 0).body
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				var thread Thread
+				if err := json.Unmarshal(body, &thread); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLoadThread(b *testing.B) {
+	for _, size := range benchSizes {
+		server := httptest.NewServer(newBenchServer(size, 0))
+		client := newTestClient(server)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := client.LoadThread(context.Background(), "g", 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		server.Close()
+	}
+}
+
+// One watcher poll of a thread that gains churn replies each time and loses as many old ones.
+func BenchmarkWatcherPoll(b *testing.B) {
+	for _, size := range benchSizes {
+		for _, churn := range []int{0, 5} {
+			server := httptest.NewServer(newBenchServer(size, churn))
+			w := &ThreadWatcher{Board: "g", ID: 1}
+			client := newTestClient(server)
+			w.poll(context.Background(), client)
+			b.Run(fmt.Sprintf("%d/churn%d", size, churn), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					for _, event := range w.poll(context.Background(), client) {
+						if event.Kind == WatchError {
+							b.Fatal(event.Err)
+						}
+					}
+				}
+			})
+			server.Close()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package fourchan
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// What kind of page was served instead of JSON.
+type BlockKind int
+
+const (
+	// Some other HTML page, often from a captive portal or a misconfigured proxy or API URL.
+	BlockedHTML BlockKind = iota
+	// A Cloudflare challenge, served when requests look automated.
+	BlockedChallenge
+	// 4chan's page saying the address is banned or blocked.
+	BlockedBanned
+)
+
+// Name the kind of page.
+func (k BlockKind) String() string {
+	switch k {
+	case BlockedHTML:
+		return "an HTML page"
+	case BlockedChallenge:
+		return "a Cloudflare challenge"
+	case BlockedBanned:
+		return "a ban page"
+	}
+	return fmt.Sprintf("BlockKind(%d)", int(k))
+}
+
+// Custom error for an HTML page served where the API's JSON was expected.
+// Retrying soon won't help, so programs running unattended should alert on it rather than carry on.
+type BlockedError struct {
+	URL        string
+	Kind       BlockKind
+	StatusCode int
+}
+
+// Say what was served and what to do about it.
+func (e BlockedError) Error() string {
+	msg := fmt.Sprintf("Fetching %s: got %v instead of JSON", e.URL, e.Kind)
+	switch e.Kind {
+	case BlockedChallenge:
+		return msg + ", slow down and check the user agent, then try again later"
+	case BlockedBanned:
+		return msg + ", this address is banned or blocked, see https://www.4chan.org/banned"
+	}
+	return msg + ", check the API URL and any proxy in between"
+}
+
+// Phrases in the pages Cloudflare challenges with, lower cased.
+var challengeMarkers = []string{"<title>just a moment...</title>", "challenge-platform", "cf-chl", "cf_chl"}
+
+// Phrases in 4chan's ban and block pages, lower cased.
+var banMarkers = []string{"you are banned", "you have been banned", "<title>4chan - banned</title>", "your ip address has been blocked"}
+
+// Work out whether a response is a challenge or ban page, or with html set any HTML page.
+// Only HTML bodies are looked through, so JSON quoting the marker phrases, e.g. in a comment, is never mistaken for one.
+func blockedPage(url string, resp *http.Response, body []byte, html bool) (BlockedError, bool) {
+	blocked := BlockedError{URL: url, StatusCode: resp.StatusCode}
+	if resp.Header.Get("Cf-Mitigated") == "challenge" {
+		blocked.Kind = BlockedChallenge
+		return blocked, true
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("<")) {
+		return blocked, false
+	}
+
+	page := strings.ToLower(string(body))
+	switch {
+	case containsAny(page, challengeMarkers):
+		blocked.Kind = BlockedChallenge
+	case containsAny(page, banMarkers):
+		blocked.Kind = BlockedBanned
+	case html:
+		blocked.Kind = BlockedHTML
+	default:
+		return blocked, false
+	}
+	return blocked, true
+}
+
+// Does s contain any of the substrings?
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
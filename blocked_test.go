@@ -0,0 +1,72 @@
+package fourchan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlockedPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/thread/1.json":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Cf-Mitigated", "challenge")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte("<html><head><title>Just a moment...</title></head></html>"))
+		case "/g/thread/2.json":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html><body><h2>You are banned! ;_;</h2></body></html>"))
+		case "/g/thread/5.json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"posts": [{"no": 5, "com": "lol you are banned from my heart"}, {"no": 6, "com": "cf-chl challenge-platform"}]}`))
+		case "/g/thread/3.json":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("\n<!DOCTYPE html><html><body>Sign in to the hotel wifi</body></html>"))
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<html><body>404</body></html>"))
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	ctx := context.Background()
+	for id, kind := range map[ThreadID]BlockKind{1: BlockedChallenge, 2: BlockedBanned, 3: BlockedHTML} {
+		_, err := c.LoadThread(ctx, "g", id)
+		blocked, ok := err.(BlockedError)
+		if !ok || blocked.Kind != kind || !strings.HasSuffix(blocked.URL, ".json") {
+			t.Fatalf("thread %d: expected %v, got %v", id, kind, err)
+		}
+		if kind == BlockedChallenge && blocked.StatusCode != http.StatusForbidden {
+			t.Fatalf("bad status %+v", blocked)
+		}
+		if !strings.Contains(err.Error(), kind.String()) {
+			t.Fatalf("thread %d: bad message %q", id, err)
+		}
+
+		// Watchers poll with their own conditional request.
+		_, err = c.loadThreadIfModified(ctx, "g", strconv.FormatUint(id, 10), time.Time{})
+		if blocked, ok := err.(BlockedError); !ok || blocked.Kind != kind {
+			t.Fatalf("watched thread %d: expected %v, got %v", id, kind, err)
+		}
+	}
+
+	// Threads that only talk about bans are still threads.
+	if thread, err := c.LoadThread(ctx, "g", 5); err != nil || len(thread.Posts) != 2 {
+		t.Fatalf("thread quoting a ban page taken for one: %v", err)
+	}
+	if thread, err := c.loadThreadIfModified(ctx, "g", "5", time.Time{}); err != nil || len(thread.Posts) != 2 {
+		t.Fatalf("watched thread quoting a ban page taken for one: %v", err)
+	}
+
+	// Ordinary HTML error pages are still what they were.
+	if _, err := c.LoadThread(ctx, "g", 4); err != (ThreadNotFoundError{"g", "4"}) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
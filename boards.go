@@ -0,0 +1,262 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Posting cooldowns of a board in seconds.
+type Cooldowns struct {
+	// Between creating threads.
+	Threads int `json:"threads"`
+	// Between replies.
+	Replies int `json:"replies"`
+	// Between replies with images.
+	Images int `json:"images"`
+}
+
+// A board and its settings as listed in boards.json.
+// Note that some fields are optional and may contain only their default values.
+// https://github.com/4chan/4chan-API
+type Board struct {
+	// Work safe board?
+	WorkSafe bool
+	// Does the board have an archive?
+	IsArchived bool
+	// Can images be spoilered?
+	Spoilers bool
+	// Does the board have custom spoiler images?
+	CustomSpoilers bool
+	// Do posts show country flags?
+	CountryFlags bool
+	// Do posts have poster IDs?
+	UserIDs bool
+	// Is [code] markup supported?
+	CodeTags bool
+	// Is [sjis] markup supported?
+	SJISTags bool
+	// Is [math] markup supported?
+	MathTags bool
+	// Can images be drawn with oekaki?
+	Oekaki bool
+	// Text only board?
+	TextOnly bool
+	// Are names disabled?
+	ForcedAnon bool
+	// Can webms have audio?
+	WebmAudio bool
+	// Must new threads have a subject?
+	RequireSubject bool
+
+	// The board's short name, e.g. "g"
+	Name string `json:"board"`
+	// The board's full name, e.g. "Technology"
+	Title string `json:"title"`
+	// SEO description
+	Description string `json:"meta_description"`
+
+	// Threads per index page
+	PerPage int `json:"per_page"`
+	// Number of index pages
+	Pages int `json:"pages"`
+
+	// Largest file allowed in bytes
+	MaxFileSize int `json:"max_filesize"`
+	// Largest webm allowed in bytes
+	MaxWebmFileSize int `json:"max_webm_filesize"`
+	// Longest comment allowed in characters
+	MaxCommentChars int `json:"max_comment_chars"`
+	// Longest webm allowed in seconds
+	MaxWebmDuration int `json:"max_webm_duration"`
+	// Smallest image allowed
+	MinImageWidth  int `json:"min_image_width"`
+	MinImageHeight int `json:"min_image_height"`
+
+	// Replies after which a thread stops bumping
+	BumpLimit int `json:"bump_limit"`
+	// Images after which a thread stops taking images
+	ImageLimit int `json:"image_limit"`
+
+	// How long to wait between posts
+	Cooldowns Cooldowns `json:"cooldowns"`
+
+	// Board specific flags keyed by code, e.g. on /pol/ or /mlp/
+	BoardFlags map[string]string `json:"board_flags"`
+}
+
+// Custom unmarshaler for a Board struct.
+// We have to handle the conversion from ints to bools :(
+func (b *Board) UnmarshalJSON(data []byte) error {
+	type Alias Board
+	tmp := &struct {
+		*Alias
+
+		WorkSafeInt       int `json:"ws_board"`
+		IsArchivedInt     int `json:"is_archived"`
+		SpoilersInt       int `json:"spoilers"`
+		CustomSpoilersInt int `json:"custom_spoilers"`
+		CountryFlagsInt   int `json:"country_flags"`
+		UserIDsInt        int `json:"user_ids"`
+		CodeTagsInt       int `json:"code_tags"`
+		SJISTagsInt       int `json:"sjis_tags"`
+		MathTagsInt       int `json:"math_tags"`
+		OekakiInt         int `json:"oekaki"`
+		TextOnlyInt       int `json:"text_only"`
+		ForcedAnonInt     int `json:"forced_anon"`
+		WebmAudioInt      int `json:"webm_audio"`
+		RequireSubjectInt int `json:"require_subject"`
+	}{
+		Alias: (*Alias)(b),
+	}
+
+	err := json.Unmarshal(data, &tmp)
+	if err != nil {
+		return err
+	}
+
+	b.WorkSafe = intToBool(tmp.WorkSafeInt)
+	b.IsArchived = intToBool(tmp.IsArchivedInt)
+	b.Spoilers = intToBool(tmp.SpoilersInt)
+	b.CustomSpoilers = intToBool(tmp.CustomSpoilersInt)
+	b.CountryFlags = intToBool(tmp.CountryFlagsInt)
+	b.UserIDs = intToBool(tmp.UserIDsInt)
+	b.CodeTags = intToBool(tmp.CodeTagsInt)
+	b.SJISTags = intToBool(tmp.SJISTagsInt)
+	b.MathTags = intToBool(tmp.MathTagsInt)
+	b.Oekaki = intToBool(tmp.OekakiInt)
+	b.TextOnly = intToBool(tmp.TextOnlyInt)
+	b.ForcedAnon = intToBool(tmp.ForcedAnonInt)
+	b.WebmAudio = intToBool(tmp.WebmAudioInt)
+	b.RequireSubject = intToBool(tmp.RequireSubjectInt)
+
+	return nil
+}
+
+// Every board on the site as served from boards.json.
+type Boards struct {
+	// The boards in the order the site lists them.
+	Boards []Board `json:"boards"`
+	// When the list was fetched.
+	FetchedAt time.Time
+}
+
+// Load the list of boards and their settings.
+func LoadBoards() (*Boards, error) {
+	return defaultClient().LoadBoards(context.Background())
+}
+
+// Load the list of boards and their settings.
+func (c *Client) LoadBoards(ctx context.Context) (*Boards, error) {
+	boards := &Boards{}
+	if _, err := c.getJSON(ctx, fmt.Sprintf("%s/boards.json", c.apiURL), boards); err != nil {
+		return nil, err
+	}
+	boards.FetchedAt = time.Now()
+
+	return boards, nil
+}
+
+// Find a board by its short name, nil if there is no such board.
+func (b *Boards) Board(name string) *Board {
+	for i := range b.Boards {
+		if b.Boards[i].Name == name {
+			return &b.Boards[i]
+		}
+	}
+	return nil
+}
+
+// Why a thread isn't being bumped by new replies.
+type BumpBlocker int
+
+const (
+	// Replies still bump the thread.
+	BumpAllowed BumpBlocker = iota
+	// The thread is archived.
+	BumpArchived
+	// The thread is closed to replies.
+	BumpClosed
+	// Stickies stay at the top and are never bumped.
+	BumpSticky
+	// The thread has reached the board's bump limit.
+	BumpPastLimit
+)
+
+// Pretty print the reason.
+func (b BumpBlocker) String() string {
+	switch b {
+	case BumpAllowed:
+		return "bumping"
+	case BumpArchived:
+		return "archived"
+	case BumpClosed:
+		return "closed"
+	case BumpSticky:
+		return "sticky"
+	case BumpPastLimit:
+		return "bump limit reached"
+	}
+	return fmt.Sprintf("BumpBlocker(%d)", int(b))
+}
+
+// Is the thread a sticky that drops its oldest replies as new ones arrive?
+func (t *Thread) IsRollingSticky() bool {
+	return len(t.Posts) > 0 && t.Posts[0].Sticky && t.Posts[0].StickyCap != 0
+}
+
+// Has the thread reached the board's bump limit?
+// Uses the OP's bumplimit flag when set and the board's limit otherwise.
+func (t *Thread) IsPastBumpLimit(board *Board) bool {
+	if len(t.Posts) == 0 {
+		return false
+	}
+	op := &t.Posts[0]
+	return op.BumpLimit || board != nil && board.BumpLimit > 0 && op.ReplyCount >= board.BumpLimit
+}
+
+// Has the thread reached the board's image limit?
+// Uses the OP's imagelimit flag when set and the board's limit otherwise.
+func (t *Thread) IsPastImageLimit(board *Board) bool {
+	if len(t.Posts) == 0 {
+		return false
+	}
+	op := &t.Posts[0]
+	return op.ImageLimit || board != nil && board.ImageLimit > 0 && op.ImageCount >= board.ImageLimit
+}
+
+// Number of replies left before the thread stops bumping, 0 once it has.
+func (t *Thread) RepliesUntilBumpLimit(board *Board) int {
+	if len(t.Posts) == 0 || t.IsPastBumpLimit(board) || board == nil {
+		return 0
+	}
+	return board.BumpLimit - t.Posts[0].ReplyCount
+}
+
+// Number of images left before the thread stops taking them, 0 once it has.
+func (t *Thread) ImagesUntilImageLimit(board *Board) int {
+	if len(t.Posts) == 0 || t.IsPastImageLimit(board) || board == nil {
+		return 0
+	}
+	return board.ImageLimit - t.Posts[0].ImageCount
+}
+
+// Why the thread isn't bumped by new replies, BumpAllowed if it is.
+func (t *Thread) BumpBlocker(board *Board) BumpBlocker {
+	if len(t.Posts) == 0 {
+		return BumpAllowed
+	}
+	op := &t.Posts[0]
+	switch {
+	case op.Archived:
+		return BumpArchived
+	case op.Closed:
+		return BumpClosed
+	case op.Sticky:
+		return BumpSticky
+	case t.IsPastBumpLimit(board):
+		return BumpPastLimit
+	}
+	return BumpAllowed
+}
@@ -0,0 +1,75 @@
+package fourchan
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadBoards(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/boards.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"boards": [
+			{"board": "g", "title": "Technology", "ws_board": 1, "per_page": 15, "pages": 10, "bump_limit": 310,
+			 "image_limit": 150, "code_tags": 1, "cooldowns": {"threads": 600, "replies": 60, "images": 60}},
+			{"board": "pol", "title": "Politically Incorrect", "ws_board": 0, "country_flags": 1, "user_ids": 1,
+			 "board_flags": {"TR": "Tree Hugger"}}
+		]}`)
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	boards, err := LoadBoards()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := boards.Board("g")
+	if g == nil || !g.WorkSafe || !g.CodeTags || g.BumpLimit != 310 || g.Cooldowns.Threads != 600 {
+		t.Fatalf("bad board %+v", g)
+	}
+	pol := boards.Board("pol")
+	if pol == nil || pol.WorkSafe || !pol.CountryFlags || !pol.UserIDs || pol.BoardFlags["TR"] != "Tree Hugger" {
+		t.Fatalf("bad board %+v", pol)
+	}
+	if boards.Board("nope") != nil {
+		t.Fatal("found a missing board")
+	}
+}
+
+func TestBumpLimits(t *testing.T) {
+	board := &Board{BumpLimit: 300, ImageLimit: 150}
+
+	thread := &Thread{Posts: []Post{{Meta: Meta{ReplyCount: 280, ImageCount: 150}}}}
+	if thread.IsPastBumpLimit(board) || thread.RepliesUntilBumpLimit(board) != 20 {
+		t.Fatal("bad bump limit")
+	}
+	if !thread.IsPastImageLimit(board) || thread.ImagesUntilImageLimit(board) != 0 {
+		t.Fatal("bad image limit")
+	}
+	if thread.BumpBlocker(board) != BumpAllowed {
+		t.Fatalf("blocked by %v", thread.BumpBlocker(board))
+	}
+
+	thread.Posts[0].ReplyCount = 300
+	if thread.BumpBlocker(board) != BumpPastLimit {
+		t.Fatalf("blocked by %v", thread.BumpBlocker(board))
+	}
+
+	// The OP's flag wins when we don't know the board.
+	flagged := &Thread{Posts: []Post{{Meta: Meta{BumpLimit: true}}}}
+	if !flagged.IsPastBumpLimit(nil) {
+		t.Fatal("ignored bumplimit flag")
+	}
+
+	sticky := &Thread{Posts: []Post{{Meta: Meta{Sticky: true, StickyCap: 1}}}}
+	if sticky.BumpBlocker(board) != BumpSticky || !sticky.IsRollingSticky() {
+		t.Fatal("bad sticky")
+	}
+}
@@ -0,0 +1,127 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// A whole board at one point in time: its catalog and every thread in it.
+type BoardSnapshot struct {
+	Board string
+	// The catalog the threads were listed in.
+	Catalog *Catalog
+	// The threads in catalog order. Each keeps its own FetchedAt.
+	Threads []*Thread
+	// Threads in the catalog that were gone by the time they were loaded.
+	Missing []ThreadID
+	// When the catalog was fetched, which is the time the snapshot is of.
+	TakenAt time.Time
+	// When the last thread was fetched.
+	FinishedAt time.Time
+}
+
+// Capture a whole board, see Client.SnapshotBoard.
+func SnapshotBoard(board string) (*BoardSnapshot, error) {
+	return defaultClient().SnapshotBoard(context.Background(), board)
+}
+
+// Capture a whole board by loading its catalog and then every thread in it.
+// Loading the threads takes a while under the client's rate limit, so the board moves on meanwhile,
+// the threads say when each was fetched. Threads that fail to load don't stop the rest, the snapshot
+// is returned without them along with a BatchError listing them.
+func (c *Client) SnapshotBoard(ctx context.Context, board string) (*BoardSnapshot, error) {
+	catalog, err := c.LoadCatalog(ctx, board)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &BoardSnapshot{Board: board, Catalog: catalog, TakenAt: catalog.FetchedAt}
+	var failures batchErrors
+	for _, page := range catalog.Pages {
+		for _, summary := range page.Threads {
+			thread, err := c.LoadThread(ctx, board, summary.PostNumber)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if _, gone := err.(ThreadNotFoundError); gone {
+				snapshot.Missing = append(snapshot.Missing, summary.PostNumber)
+				continue
+			}
+			failures.add(ThreadRef{board, summary.PostNumber}.String(), err)
+			if err == nil {
+				snapshot.Threads = append(snapshot.Threads, thread)
+			}
+		}
+	}
+	snapshot.FinishedAt = time.Now()
+
+	return snapshot, failures.err()
+}
+
+// How a board snapshot is laid out on disk, with threads as Thread.Save writes them.
+type boardSnapshotFile struct {
+	Board      string        `json:"board"`
+	TakenAt    time.Time     `json:"taken_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Catalog    catalogFile   `json:"catalog"`
+	Threads    []*threadFile `json:"threads"`
+	Missing    []ThreadID    `json:"missing,omitempty"`
+}
+
+type catalogFile struct {
+	FetchedAt  time.Time     `json:"fetched_at"`
+	ModifiedAt time.Time     `json:"modified_at"`
+	Pages      []CatalogPage `json:"pages"`
+}
+
+// Write the snapshot to path as one JSON document.
+// Like Thread.Save, the file is replaced in one step and flushed to disk.
+func (s *BoardSnapshot) Save(path string) error {
+	return saveFile(path, true, func(w io.Writer) error {
+		f := &boardSnapshotFile{
+			Board:      s.Board,
+			TakenAt:    s.TakenAt,
+			FinishedAt: s.FinishedAt,
+			Threads:    make([]*threadFile, len(s.Threads)),
+			Missing:    s.Missing,
+		}
+		if s.Catalog != nil {
+			f.Catalog = catalogFile{s.Catalog.FetchedAt, s.Catalog.ModifiedAt, s.Catalog.Pages}
+		}
+		for i, t := range s.Threads {
+			f.Threads[i] = &threadFile{t.Board, t.FetchedAt, t.ModifiedAt, t.Archive, t.Posts}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(f)
+	})
+}
+
+// Load a board snapshot written by BoardSnapshot.Save.
+func LoadBoardSnapshot(path string) (*BoardSnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f boardSnapshotFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	s := &BoardSnapshot{
+		Board:      f.Board,
+		Catalog:    &Catalog{Pages: f.Catalog.Pages, Board: f.Board, FetchedAt: f.Catalog.FetchedAt, ModifiedAt: f.Catalog.ModifiedAt},
+		Threads:    make([]*Thread, len(f.Threads)),
+		Missing:    f.Missing,
+		TakenAt:    f.TakenAt,
+		FinishedAt: f.FinishedAt,
+	}
+	for i, t := range f.Threads {
+		s.Threads[i] = &Thread{Posts: t.Posts, Board: t.Board, FetchedAt: t.FetchedAt, ModifiedAt: t.ModifiedAt, Archive: t.Archive}
+	}
+	return s, nil
+}
@@ -0,0 +1,71 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotBoard(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/catalog.json":
+			fmt.Fprint(w, `[
+				{"page": 1, "threads": [{"no": 10, "sub": "/dpt/", "last_replies": [{"no": 12, "resto": 10}]}, {"no": 20}]},
+				{"page": 2, "threads": [{"no": 30}, {"no": 40}]}
+			]`)
+		case "/g/thread/10.json":
+			fmt.Fprint(w, `{"posts": [{"no": 10, "sub": "/dpt/"}, {"no": 12, "resto": 10}]}`)
+		case "/g/thread/30.json":
+			fmt.Fprint(w, `{"posts": [{"no": 30}]}`)
+		case "/g/thread/40.json":
+			http.Error(w, "broken", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	snapshot, err := newTestClient(server).SnapshotBoard(context.Background(), "g")
+	if be, ok := err.(BatchError); !ok || be.Total != 3 || len(be.Failures) != 1 || be.Failures[0].Item != "/g/40" {
+		t.Fatalf("expected thread 40 to fail, got %v", err)
+	}
+	if len(snapshot.Threads) != 2 || snapshot.Threads[0].Posts[0].PostNumber != 10 || snapshot.Threads[1].Posts[0].PostNumber != 30 {
+		t.Fatalf("bad threads %+v", snapshot.Threads)
+	}
+	if len(snapshot.Missing) != 1 || snapshot.Missing[0] != 20 {
+		t.Fatalf("bad missing threads %v", snapshot.Missing)
+	}
+	if !snapshot.TakenAt.Equal(snapshot.Catalog.FetchedAt) || snapshot.FinishedAt.Before(snapshot.TakenAt) {
+		t.Fatalf("bad times %v %v", snapshot.TakenAt, snapshot.FinishedAt)
+	}
+
+	dir, err := ioutil.TempDir("", "fourchan-board")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "g.json")
+	if err = snapshot.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBoardSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Board != "g" || !loaded.TakenAt.Equal(snapshot.TakenAt) || len(loaded.Threads) != 2 || len(loaded.Missing) != 1 {
+		t.Fatalf("bad loaded snapshot %+v", loaded)
+	}
+	if loaded.Threads[0].Board != "g" || len(loaded.Threads[0].Posts) != 2 || loaded.Threads[0].Posts[0].Subject != "/dpt/" {
+		t.Fatalf("bad loaded thread %+v", loaded.Threads[0])
+	}
+	entry := loaded.Catalog.Thread(10)
+	if len(loaded.Catalog.Pages) != 2 || entry == nil || entry.Subject != "/dpt/" || len(entry.LastReplies) != 1 {
+		t.Fatalf("bad loaded catalog %+v", loaded.Catalog)
+	}
+}
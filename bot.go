@@ -0,0 +1,230 @@
+package fourchan
+
+import (
+	"context"
+	"time"
+)
+
+// A post a Bot saw appear.
+type BotPost struct {
+	Board string
+	// The thread the post is in, as loaded when the post was seen.
+	Thread *Thread
+	// The post itself, one of the thread's posts.
+	Post *Post
+}
+
+// Is the post the OP of a new thread?
+func (p BotPost) IsOP() bool {
+	return len(p.Thread.Posts) > 0 && &p.Thread.Posts[0] == p.Post
+}
+
+// Watches boards for new threads and posts and hands them to the handlers registered with it.
+//
+//	bot := &fourchan.Bot{Boards: []string{"g"}}
+//	bot.OnKeyword("thinkpad", func(p fourchan.BotPost) error {
+//		fmt.Println(p.Thread.Ref(), p.Post.PlainText())
+//		return nil
+//	})
+//	err := bot.Run(ctx)
+//
+// The first poll of each board only learns what is already there, later ones report what appeared since.
+type Bot struct {
+	// The boards to watch.
+	Boards []string
+	// Time between polls of each board's catalog, defaults to DefaultWatchInterval.
+	Interval time.Duration
+	// The client to poll with, defaults to DefaultClient.
+	Client *Client
+
+	newThread []func(*Thread) error
+	newPost   []func(BotPost) error
+	onError   []func(error)
+
+	// The last post and last_modified seen for each live thread, by board.
+	seen map[string]map[ThreadID]botThread
+}
+
+// How far the bot got with a thread.
+type botThread struct {
+	// The last post every handler took.
+	last PostID
+	// The thread's last_modified once all of it was handled, zero until then.
+	modified uint64
+	// Have the new thread handlers taken the thread?
+	announced bool
+}
+
+// Call h with each new thread, after its OP has been handed to the post handlers.
+func (b *Bot) OnNewThread(h func(*Thread) error) {
+	b.newThread = append(b.newThread, h)
+}
+
+// Call h with each new post, OPs included.
+func (b *Bot) OnNewPost(h func(BotPost) error) {
+	b.newPost = append(b.newPost, h)
+}
+
+// Call h with each new post whose subject or comment contains keyword, ignoring case and accents.
+func (b *Bot) OnKeyword(keyword string, h func(BotPost) error) {
+	b.OnMatch(TextMatcher(keyword), h)
+}
+
+// Call h with each new post match accepts, e.g. a Filter's Match.
+func (b *Bot) OnMatch(match func(*Post) bool, h func(BotPost) error) {
+	b.OnNewPost(func(p BotPost) error {
+		if !match(p.Post) {
+			return nil
+		}
+		return h(p)
+	})
+}
+
+// Call h with each poll that fails. Failed polls are retried at the next interval either way.
+func (b *Bot) OnError(h func(error)) {
+	b.onError = append(b.onError, h)
+}
+
+// Poll the boards until ctx is done, which is the error returned, or a handler returns an error,
+// which stops the bot and is returned as is. Running the bot again carries on where it stopped:
+// the post or thread whose handler failed is handed out again, the ones handled before it aren't.
+func (b *Bot) Run(ctx context.Context) error {
+	interval := b.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	client := b.Client
+	if client == nil {
+		client = defaultClient()
+	}
+	if b.seen == nil {
+		b.seen = map[string]map[ThreadID]botThread{}
+	}
+
+	for {
+		for _, board := range b.Boards {
+			if err := b.poll(ctx, client, board); err != nil {
+				return err
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Check a board once and hand what appeared to the handlers.
+// Only handler errors and the context's are returned, anything else goes to the error handlers.
+func (b *Bot) poll(ctx context.Context, client *Client, board string) error {
+	catalog, err := client.LoadCatalog(ctx, board)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		b.fail(err)
+		return nil
+	}
+
+	seen, seeded := b.seen[board]
+	if !seeded {
+		seen = map[ThreadID]botThread{}
+		b.seen[board] = seen
+	}
+	live := map[ThreadID]bool{}
+
+	for _, page := range catalog.Pages {
+		for i := range page.Threads {
+			entry := &page.Threads[i]
+			id := entry.PostNumber
+			live[id] = true
+			known, ok := seen[id]
+			if !seeded {
+				seen[id] = botThread{catalogLastPost(entry), entry.LastModified, true}
+				continue
+			}
+			if ok && known.modified == entry.LastModified {
+				continue
+			}
+
+			thread, err := client.LoadThread(ctx, board, id)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if _, gone := err.(ThreadNotFoundError); gone {
+				delete(live, id)
+				continue
+			}
+			if err != nil {
+				b.fail(err)
+				continue
+			}
+			if len(thread.Posts) == 0 {
+				continue
+			}
+
+			if err = b.handle(board, thread, seen, known); err != nil {
+				return err
+			}
+			progress := seen[id]
+			progress.modified = entry.LastModified
+			seen[id] = progress
+		}
+	}
+
+	// Forget threads that fell off the board.
+	for id := range seen {
+		if !live[id] {
+			delete(seen, id)
+		}
+	}
+	return nil
+}
+
+// Hand the posts in the thread after the ones handled so far to the post handlers, then the thread
+// to the new thread handlers unless they already had it. Progress is recorded in seen as it is made.
+func (b *Bot) handle(board string, thread *Thread, seen map[ThreadID]botThread, progress botThread) error {
+	id := thread.Posts[0].PostNumber
+	for i := range thread.Posts {
+		post := &thread.Posts[i]
+		if post.PostNumber <= progress.last {
+			continue
+		}
+		for _, h := range b.newPost {
+			if err := h(BotPost{board, thread, post}); err != nil {
+				return err
+			}
+		}
+		progress.last = post.PostNumber
+		seen[id] = progress
+	}
+
+	if !progress.announced {
+		for _, h := range b.newThread {
+			if err := h(thread); err != nil {
+				return err
+			}
+		}
+		progress.announced = true
+		seen[id] = progress
+	}
+	return nil
+}
+
+func (b *Bot) fail(err error) {
+	for _, h := range b.onError {
+		h(err)
+	}
+}
+
+// The number of the latest post a catalog entry shows.
+func catalogLastPost(entry *CatalogThread) PostID {
+	if n := len(entry.LastReplies); n > 0 {
+		return entry.LastReplies[n-1].PostNumber
+	}
+	return entry.PostNumber
+}
@@ -0,0 +1,79 @@
+package fourchan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBot(t *testing.T) {
+	var mu sync.Mutex
+	catalog := `[{"page": 1, "threads": [{"no": 1, "last_modified": 100, "last_replies": [{"no": 2}]}]}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/g/catalog.json":
+			fmt.Fprint(w, catalog)
+		case "/g/thread/1.json":
+			fmt.Fprint(w, `{"posts": [{"no": 1}, {"no": 2}, {"no": 3, "com": "Anyone got a ThinkPad?"}]}`)
+		case "/g/thread/4.json":
+			fmt.Fprint(w, `{"posts": [{"no": 4, "sub": "thinkpad general"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	bot := &Bot{Boards: []string{"g"}, Interval: 5 * time.Millisecond, Client: newTestClient(server)}
+	var posts, keyword []PostID
+	var threads []ThreadID
+	bot.OnNewPost(func(p BotPost) error {
+		posts = append(posts, p.Post.PostNumber)
+		return nil
+	})
+	bot.OnNewThread(func(thread *Thread) error {
+		threads = append(threads, thread.Posts[0].PostNumber)
+		return nil
+	})
+	done := errors.New("done")
+	bot.OnKeyword("thinkpad", func(p BotPost) error {
+		keyword = append(keyword, p.Post.PostNumber)
+		// Fail the first time only, so the post is handed out again.
+		if p.IsOP() && len(keyword) == 1 {
+			return done
+		}
+		return nil
+	})
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		catalog = `[{"page": 1, "threads": [{"no": 4, "last_modified": 300}, {"no": 1, "last_modified": 200, "last_replies": [{"no": 3}]}]}]`
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bot.Run(ctx); err != done {
+		t.Fatalf("handler error not returned: %v", err)
+	}
+	if fmt.Sprint(posts) != "[4]" || fmt.Sprint(threads) != "[]" || fmt.Sprint(keyword) != "[4]" {
+		t.Fatalf("bad first changes, posts %v threads %v keyword %v", posts, threads, keyword)
+	}
+
+	// The bot picks up where it stopped, with the post whose handler failed, and announces the new thread.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := bot.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("bot didn't run until cancelled: %v", err)
+	}
+	if fmt.Sprint(posts) != "[4 4 3]" || fmt.Sprint(threads) != "[4]" || fmt.Sprint(keyword) != "[4 4 3]" {
+		t.Fatalf("bad changes, posts %v threads %v keyword %v", posts, threads, keyword)
+	}
+}
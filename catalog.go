@@ -0,0 +1,129 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// A thread as listed in a board's catalog.json, its OP and latest replies.
+type CatalogThread struct {
+	// The OP.
+	Post
+	// The most recent replies, oldest first.
+	LastReplies []Post `json:"last_replies"`
+}
+
+// Custom unmarshaler for a CatalogThread.
+// The OP's fields and the replies share one object, so each is decoded on its own.
+func (c *CatalogThread) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Post); err != nil {
+		return err
+	}
+
+	tmp := &struct {
+		LastReplies []Post `json:"last_replies"`
+	}{}
+	if err := json.Unmarshal(data, tmp); err != nil {
+		return err
+	}
+	c.LastReplies = tmp.LastReplies
+
+	return nil
+}
+
+// Custom marshaler for a CatalogThread, the other way around from UnmarshalJSON.
+// The OP's marshaler would otherwise leave the replies out.
+func (c *CatalogThread) MarshalJSON() ([]byte, error) {
+	op, err := json.Marshal(&c.Post)
+	if err != nil || len(c.LastReplies) == 0 {
+		return op, err
+	}
+	replies, err := json.Marshal(c.LastReplies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Put the replies in the OP's object, before its closing brace.
+	data := append(op[:len(op)-1:len(op)-1], `,"last_replies":`...)
+	data = append(data, replies...)
+	return append(data, '}'), nil
+}
+
+// One index page of a board's catalog.
+type CatalogPage struct {
+	// The page number, starting at 1.
+	Page int `json:"page"`
+	// The threads on the page in index order.
+	Threads []CatalogThread `json:"threads"`
+}
+
+// Every live thread on a board with its OP, as served from /{board}/catalog.json.
+type Catalog struct {
+	// The index pages.
+	Pages []CatalogPage
+	// The board the catalog is from.
+	Board string
+	// When the catalog was fetched.
+	FetchedAt time.Time
+	// When the catalog last changed, from Last-Modified.
+	ModifiedAt time.Time
+}
+
+// Load a board's catalog.
+func LoadCatalog(board string) (*Catalog, error) {
+	return defaultClient().LoadCatalog(context.Background(), board)
+}
+
+// Load a board's catalog.
+func (c *Client) LoadCatalog(ctx context.Context, board string) (*Catalog, error) {
+	catalog := &Catalog{Board: board}
+	header, err := c.getJSON(ctx, fmt.Sprintf("%s/%s/catalog.json", c.apiURL, board), &catalog.Pages)
+	if err != nil {
+		return nil, err
+	}
+	catalog.FetchedAt = time.Now()
+	catalog.ModifiedAt, _ = http.ParseTime(header.Get("Last-Modified"))
+	quirks := QuirksFor(board)
+	for _, page := range catalog.Pages {
+		for i := range page.Threads {
+			entry := &page.Threads[i]
+			quirks.fixPost(&entry.Post)
+			quirks.fixPosts(entry.LastReplies)
+		}
+	}
+
+	return catalog, nil
+}
+
+// The catalog entry for a thread, nil if it isn't in the catalog.
+func (c *Catalog) Thread(id uint64) *CatalogThread {
+	for i := range c.Pages {
+		for j := range c.Pages[i].Threads {
+			if c.Pages[i].Threads[j].PostNumber == id {
+				return &c.Pages[i].Threads[j]
+			}
+		}
+	}
+	return nil
+}
+
+// Every thread in the catalog as a preview in index order, see Thread.IsPreview and Thread.Expand.
+func (c *Catalog) Threads() []*Thread {
+	var threads []*Thread
+	for _, page := range c.Pages {
+		for i := range page.Threads {
+			entry := &page.Threads[i]
+			thread := &Thread{
+				Posts:      append([]Post{entry.Post}, entry.LastReplies...),
+				Board:      c.Board,
+				FetchedAt:  c.FetchedAt,
+				ModifiedAt: c.ModifiedAt,
+			}
+			threads = append(threads, thread)
+		}
+	}
+	return threads
+}
@@ -0,0 +1,50 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/g/catalog.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"page": 1, "threads": [
+				{"no": 10, "sub": "/dpt/", "sticky": 1, "replies": 3, "omitted_posts": 1,
+				 "last_replies": [{"no": 12, "resto": 10}, {"no": 13, "resto": 10}]}
+			]},
+			{"page": 2, "threads": [{"no": 20, "replies": 0}]}
+		]`)
+	}))
+	defer server.Close()
+
+	catalog, err := newTestClient(server).LoadCatalog(context.Background(), "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Pages) != 2 || catalog.Board != "g" || catalog.FetchedAt.IsZero() {
+		t.Fatalf("bad catalog %+v", catalog)
+	}
+
+	entry := catalog.Thread(10)
+	if entry == nil || entry.Subject != "/dpt/" || !entry.Sticky || len(entry.LastReplies) != 2 || entry.LastReplies[1].PostNumber != 13 {
+		t.Fatalf("bad entry %+v", entry)
+	}
+	if catalog.Thread(11) != nil {
+		t.Fatal("found a missing thread")
+	}
+
+	threads := catalog.Threads()
+	if len(threads) != 2 || len(threads[0].Posts) != 3 || threads[0].Board != "g" {
+		t.Fatalf("bad threads %+v", threads)
+	}
+	if !threads[0].IsPreview() || threads[1].IsPreview() {
+		t.Fatal("bad preview detection")
+	}
+}
@@ -0,0 +1,431 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The API rules ask for no more than one request per second.
+const DefaultRateLimit = time.Second
+
+// Talks to the 4chan API.
+// A Client is safe for concurrent use, and every request made through it
+// shares one rate limiter so concurrent callers don't get us banned.
+type Client struct {
+	httpClient *http.Client
+	apiURL     string
+	mediaURL   string
+	userAgent  string
+	limiter    *rateLimiter
+	archives   []ArchiveProvider
+	postHook   func(*Post) error
+	dumpDir    string
+
+	// Retries, see WithRetries and WithOperationTimeout.
+	attempts         int
+	backoff          time.Duration
+	operationTimeout time.Duration
+
+	// The last response GetJSON had for each URL with a Last-Modified header, see GetJSON.
+	cacheMu   sync.Mutex
+	jsonCache map[string]cachedJSON
+}
+
+// A response kept to ask for again with If-Modified-Since.
+type cachedJSON struct {
+	lastModified string
+	body         []byte
+}
+
+// Configures a Client.
+type Option func(*Client)
+
+// Use the given HTTP client, e.g. to set timeouts or a proxy.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// Wait at least interval between requests, 0 disables rate limiting.
+func WithRateLimit(interval time.Duration) Option {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(interval)
+	}
+}
+
+// Send the given User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// Load the JSON API from somewhere other than https://a.4cdn.org, e.g. a mirror or test server.
+func WithAPIURL(url string) Option {
+	return func(c *Client) {
+		c.apiURL = url
+	}
+}
+
+// Download files from somewhere other than https://i.4cdn.org.
+func WithMediaURL(url string) Option {
+	return func(c *Client) {
+		c.mediaURL = url
+	}
+}
+
+// Create a client, by default using http.DefaultClient limited to one request per second.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient: http.DefaultClient,
+		apiURL:     "https://a.4cdn.org",
+		mediaURL:   mediaURL,
+		limiter:    newRateLimiter(DefaultRateLimit),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// The client the package level functions use.
+// Replace it with SetDefaultOptions, setting it directly is only safe before anything uses it.
+var DefaultClient = NewClient()
+
+// Guards DefaultClient.
+var defaultMu sync.RWMutex
+
+// Replace DefaultClient with a client made with opts, e.g. to set a user agent for the package level functions.
+// This is safe at any time, though calls already under way finish with the old client and its rate limit,
+// so it's best done once at start up.
+func SetDefaultOptions(opts ...Option) {
+	c := NewClient(opts...)
+	defaultMu.Lock()
+	DefaultClient = c
+	defaultMu.Unlock()
+}
+
+// The current DefaultClient.
+func defaultClient() *Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return DefaultClient
+}
+
+// Spaces requests out by a fixed interval.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	return &rateLimiter{interval: interval}
+}
+
+// Block until the next request may be made or ctx is done.
+// A caller that gives up hands its slot back, unless someone has already queued up behind it.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r.interval <= 0 {
+		return ctx.Err()
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	at := r.next
+	if at.Before(now) {
+		at = now
+	}
+	r.next = at.Add(r.interval)
+	r.mu.Unlock()
+
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		r.mu.Lock()
+		if r.next.Equal(at.Add(r.interval)) {
+			r.next = at
+		}
+		r.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Fetch any JSON endpoint of the API, see Client.GetJSON.
+func GetJSON(path string, v interface{}) error {
+	return defaultClient().GetJSON(context.Background(), path, v)
+}
+
+// Fetch any JSON endpoint of the API, e.g. /boards.json, and decode it into v, for endpoints this
+// package doesn't model yet. path is relative to the API's URL. The request goes through the rate limit,
+// user agent and retries like any other, and failures come back as the same errors.
+// Responses with a Last-Modified header are kept, and asked for again with If-Modified-Since,
+// so fetching an endpoint that hasn't changed decodes the kept copy rather than downloading it again.
+func (c *Client) GetJSON(ctx context.Context, path string, v interface{}) error {
+	url := c.apiURL + "/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	c.cacheMu.Lock()
+	cached, ok := c.jsonCache[url]
+	c.cacheMu.Unlock()
+	if ok {
+		req.Header.Set("If-Modified-Since", cached.lastModified)
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		return c.decodeBytes(url, resp, cached.body, v)
+	case resp.StatusCode != http.StatusOK:
+		return responseError(url, resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err = c.decodeBytes(url, resp, body, v); err != nil {
+		return err
+	}
+	if modified := resp.Header.Get("Last-Modified"); modified != "" {
+		c.cacheMu.Lock()
+		if c.jsonCache == nil {
+			c.jsonCache = map[string]cachedJSON{}
+		}
+		c.jsonCache[url] = cachedJSON{modified, body}
+		c.cacheMu.Unlock()
+	}
+	return nil
+}
+
+// Fetch url and decode the JSON response into v.
+// Returns the response headers.
+func (c *Client) getJSON(ctx context.Context, url string, v interface{}) (http.Header, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(url, resp)
+	}
+
+	if err = c.decodeBody(url, resp, v); err != nil {
+		return nil, err
+	}
+	return resp.Header, nil
+}
+
+// Decode the JSON body of a successful response into v.
+// Returns BlockedError for an HTML page in place of the JSON, the post hook's own error when it
+// fails, and DecodeError when the body doesn't decode.
+func (c *Client) decodeBody(url string, resp *http.Response, v interface{}) error {
+	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return c.decodeBytes(url, resp, bodyBytes, v)
+}
+
+// Decode a body already read from resp into v, see decodeBody.
+func (c *Client) decodeBytes(url string, resp *http.Response, bodyBytes []byte, v interface{}) error {
+	if blocked, ok := blockedPage(url, resp, bodyBytes, true); ok {
+		return blocked
+	}
+
+	err := json.Unmarshal(bodyBytes, v)
+	if he, ok := err.(postHookError); ok {
+		return he.err
+	}
+	if err != nil {
+		return c.decodeError(url, resp, bodyBytes, err)
+	}
+	return nil
+}
+
+// Custom error to indicate we are asking too often.
+type RateLimitedError struct {
+	URL string
+	// How long the server asked us to wait, zero if it didn't say.
+	RetryAfter time.Duration
+}
+
+// Say how long to back off for.
+func (e RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("Rate limited fetching %s, retry after %v", e.URL, e.RetryAfter)
+	}
+	return fmt.Sprintf("Rate limited fetching %s", e.URL)
+}
+
+// Custom error to indicate the server answered with a status we didn't expect.
+type ServerError struct {
+	URL        string
+	StatusCode int
+	// The start of the response body, which sometimes explains the problem.
+	Body string
+}
+
+// Report the status and whatever the server said.
+func (e ServerError) Error() string {
+	msg := fmt.Sprintf("Fetching %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is the failure on the server's end, so trying again later may work?
+func (e ServerError) Temporary() bool {
+	return e.StatusCode >= 500
+}
+
+// Most of an error response's body kept in a ServerError.
+const maxErrorBody = 512
+
+// Most of an error response's body looked through for a challenge or ban page.
+const maxBlockedBody = 64 << 10
+
+// Build the error for a response with an unexpected status.
+func responseError(url string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return RateLimitedError{url, retryAfter(resp.Header.Get("Retry-After"))}
+	}
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxBlockedBody))
+	if blocked, ok := blockedPage(url, resp, body, false); ok {
+		return blocked
+	}
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return ServerError{url, resp.StatusCode, strings.TrimSpace(string(body))}
+}
+
+// Parse a Retry-After header, either seconds or an HTTP date.
+func retryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Is err the response to something that doesn't exist?
+func isNotFound(err error) bool {
+	se, ok := err.(ServerError)
+	return ok && se.StatusCode == http.StatusNotFound
+}
+
+// Fetch and decode a thread from url.
+// Returns ThreadNotFoundError when the thread is gone.
+func (c *Client) loadThread(ctx context.Context, url, board, id string) (*Thread, error) {
+	thread := &Thread{}
+	header, err := c.getJSON(ctx, url, c.threadTarget(thread))
+	if isNotFound(err) {
+		return nil, ThreadNotFoundError{board, id}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stampThread(thread, board, header)
+	return thread, nil
+}
+
+// Fill in what a thread's JSON leaves out from the response it came in.
+func stampThread(thread *Thread, board string, header http.Header) {
+	thread.Board = board
+	QuirksFor(board).fixPosts(thread.Posts)
+	thread.FetchedAt = time.Now()
+	if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		thread.ModifiedAt = modified
+	}
+}
+
+// Given an URL, extract the board and thread ID then load the thread.
+func (c *Client) LoadThreadFromURL(ctx context.Context, url string) (*Thread, error) {
+	board, id, err := extractBoardAndThreadId(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.LoadThreadById(ctx, board, id)
+}
+
+// Load a thread by board and ID.
+// Threads that are gone are looked up in the archives set with WithArchiveFallback.
+func (c *Client) LoadThread(ctx context.Context, board string, id ThreadID) (*Thread, error) {
+	return c.LoadThreadById(ctx, board, strconv.FormatUint(id, 10))
+}
+
+// Load a thread by board and ID, as a string.
+// Threads that are gone are looked up in the archives set with WithArchiveFallback.
+func (c *Client) LoadThreadById(ctx context.Context, board, id string) (*Thread, error) {
+	thread, err := c.loadThread(ctx, fmt.Sprintf("%s/%s/thread/%s.json", c.apiURL, board, id), board, id)
+	if _, gone := err.(ThreadNotFoundError); gone && len(c.archives) > 0 {
+		return c.loadArchivedThread(ctx, board, id, err)
+	}
+	return thread, err
+}
+
+// Has the thread changed since t?
+// This only asks for the headers, so it is much cheaper than loading the thread.
+func (c *Client) ThreadModifiedSince(ctx context.Context, board, id string, t time.Time) (bool, error) {
+	url := fmt.Sprintf("%s/%s/thread/%s.json", c.apiURL, board, id)
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("If-Modified-Since", t.UTC().Format(http.TimeFormat))
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+		if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			return modified.After(t.Truncate(time.Second)), nil
+		}
+		return true, nil
+	case http.StatusNotFound:
+		return false, ThreadNotFoundError{board, id}
+	}
+	return false, responseError(url, resp)
+}
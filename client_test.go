@@ -0,0 +1,231 @@
+package fourchan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// A client for server without rate limiting.
+func newTestClient(server *httptest.Server) *Client {
+	return NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithHTTPClient(server.Client()))
+}
+
+func TestClientUserAgent(t *testing.T) {
+	var ua string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua = r.Header.Get("User-Agent")
+		w.Write([]byte(`{"posts": [{"no": 1}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithUserAgent("test-agent/1.0"))
+	thread, err := c.LoadThreadById(context.Background(), "g", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Board != "g" || len(thread.Posts) != 1 {
+		t.Fatalf("bad thread %+v", thread)
+	}
+	if ua != "test-agent/1.0" {
+		t.Fatalf("bad user agent %q", ua)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.Write([]byte(`{"posts": [{"no": 1}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(50*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if _, err := c.LoadThreadById(context.Background(), "g", "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < len(times); i++ {
+		if gap := times[i].Sub(times[i-1]); gap < 40*time.Millisecond {
+			t.Fatalf("requests %d and %d only %v apart", i-1, i, gap)
+		}
+	}
+}
+
+func TestClientContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"posts": [{"no": 1}]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(time.Hour))
+	if _, err := c.LoadThreadById(context.Background(), "g", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next request would wait an hour for the limiter.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.LoadThreadById(ctx, "g", "1"); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestClientStatusErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/thread/1.json":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/g/thread/2.json":
+			http.Error(w, "backend down", http.StatusBadGateway)
+		case "/g/thread/3.json":
+			w.Write([]byte(`{"posts": [`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := newTestClient(server)
+	ctx := context.Background()
+
+	_, err := c.LoadThreadById(ctx, "g", "1")
+	if limited, ok := err.(RateLimitedError); !ok || limited.RetryAfter != 30*time.Second {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+
+	_, err = c.LoadThreadById(ctx, "g", "2")
+	if se, ok := err.(ServerError); !ok || se.StatusCode != http.StatusBadGateway || se.Body != "backend down" || !se.Temporary() {
+		t.Fatalf("expected server error, got %v", err)
+	}
+
+	// Bodies that aren't JSON are still reported as such.
+	if _, err = c.LoadThreadById(ctx, "g", "3"); err == nil {
+		t.Fatal("decoded a truncated thread")
+	}
+
+	if _, err = c.LoadThreadById(ctx, "g", "4"); err != (ThreadNotFoundError{"g", "4"}) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	_, err = c.LoadBoards(ctx)
+	if se, ok := err.(ServerError); !ok || se.StatusCode != http.StatusNotFound || se.Temporary() {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d := retryAfter("120"); d != 2*time.Minute {
+		t.Fatal(d)
+	}
+	if d := retryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)); d < 59*time.Minute || d > time.Hour {
+		t.Fatal(d)
+	}
+	if d := retryAfter("soon"); d != 0 {
+		t.Fatal(d)
+	}
+}
+
+func TestClientLoadThread(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/g/thread/123.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"posts": [{"no": 123}]}`))
+	}))
+	defer server.Close()
+
+	thread, err := newTestClient(server).LoadThread(context.Background(), "g", 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thread.Posts[0].PostNumber != 123 {
+		t.Fatalf("bad thread %+v", thread)
+	}
+}
+
+func TestClientGetJSON(t *testing.T) {
+	modified := time.Unix(1500000000, 0).UTC().Format(http.TimeFormat)
+	var downloads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/g/new.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-Modified-Since") == modified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&downloads, 1)
+		w.Header().Set("Last-Modified", modified)
+		w.Write([]byte(`{"answer": 42}`))
+	}))
+	defer server.Close()
+
+	var v struct {
+		Answer int `json:"answer"`
+	}
+	c := newTestClient(server)
+	for _, path := range []string{"/g/new.json", "g/new.json"} {
+		if err := c.GetJSON(context.Background(), path, &v); err != nil || v.Answer != 42 {
+			t.Fatalf("bad response for %s, %+v %v", path, v, err)
+		}
+		v.Answer = 0
+	}
+	if downloads != 1 {
+		t.Fatalf("downloaded %d times, want the second fetch answered from the cache", downloads)
+	}
+	if err := c.GetJSON(context.Background(), "/g/old.json", &v); !isNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestSetDefaultOptions(t *testing.T) {
+	var ua atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ua.Store(r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"posts": [{"no": 1}]}`))
+	}))
+	defer server.Close()
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+
+	// Replacing the client while it's in use is safe.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			SetDefaultOptions(WithAPIURL(server.URL), WithRateLimit(0), WithUserAgent("before"))
+			LoadThread("g", 1)
+		}()
+	}
+	wg.Wait()
+
+	SetDefaultOptions(WithAPIURL(server.URL), WithRateLimit(0), WithUserAgent("after"))
+	if _, err := LoadThread("g", 1); err != nil || ua.Load() != "after" {
+		t.Fatalf("expected the new options, got %v %v", ua.Load(), err)
+	}
+}
+
+func TestRateLimiterCancelled(t *testing.T) {
+	r := newRateLimiter(time.Hour)
+	start := time.Now()
+	if err := r.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := r.wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+	if r.next.Sub(start) > 90*time.Minute {
+		t.Fatalf("cancelled wait kept its slot, next request at %v", r.next.Sub(start))
+	}
+}
@@ -0,0 +1,141 @@
+package fourchan
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matches a run of > followed by a number, a quote when there are exactly two, e.g. >>123.
+// Quotes can follow each other with nothing in between, as in >>12>>34.
+var quoteLinkRegex = regexp.MustCompile(`(>+)(\d+)`)
+
+// Converts the HTML of a comment into plain text.
+// Line breaks become newlines, all other tags are dropped and entities are decoded.
+func commentText(comment string) string {
+	var b strings.Builder
+	for len(comment) > 0 {
+		start := strings.IndexByte(comment, '<')
+		if start < 0 {
+			b.WriteString(comment)
+			break
+		}
+		b.WriteString(comment[:start])
+		comment = comment[start:]
+
+		end := strings.IndexByte(comment, '>')
+		if end < 0 {
+			// Not a tag, just a stray bracket.
+			b.WriteString(comment)
+			break
+		}
+		if tagName(comment[1:end]) == "br" {
+			b.WriteByte('\n')
+		}
+		comment = comment[end+1:]
+	}
+	return html.UnescapeString(b.String())
+}
+
+// Returns the lower cased name of a tag given the text between its brackets.
+// Closing tags keep their leading slash.
+func tagName(tag string) string {
+	tag = strings.TrimSpace(tag)
+	end := strings.IndexAny(tag, " \t\n/")
+	if strings.HasPrefix(tag, "/") {
+		end = strings.IndexAny(tag[1:], " \t\n")
+		if end >= 0 {
+			end++
+		}
+	}
+	if end > 0 {
+		tag = tag[:end]
+	}
+	return strings.ToLower(tag)
+}
+
+// The comment as plain text.
+// Line breaks become newlines, all other tags are dropped and entities are decoded.
+func (p *Post) PlainText() string {
+	return commentText(p.Comment)
+}
+
+// Post numbers the comment quotes with >>123, in the order they first appear.
+// Quotes of other boards (>>>/g/123) aren't included.
+func (p *Post) QuotedPosts() []PostID {
+	var quoted []PostID
+	seen := map[PostID]bool{}
+	for _, match := range quoteLinkRegex.FindAllStringSubmatch(p.PlainText(), -1) {
+		if len(match[1]) != 2 {
+			continue
+		}
+		no, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil || seen[no] {
+			continue
+		}
+		seen[no] = true
+		quoted = append(quoted, no)
+	}
+	return quoted
+}
+
+// The replies to each post in the thread, keyed by the post number being quoted.
+// Replies are listed in thread order, quotes of posts outside the thread are left out, see ResolveQuotes for those.
+func (t *Thread) ReplyMap() map[PostID][]PostID {
+	inThread := map[PostID]bool{}
+	for i := range t.Posts {
+		inThread[t.Posts[i].PostNumber] = true
+	}
+
+	replies := map[PostID][]PostID{}
+	for i := range t.Posts {
+		for _, quoted := range t.Posts[i].QuotedPosts() {
+			if inThread[quoted] {
+				replies[quoted] = append(replies[quoted], t.Posts[i].PostNumber)
+			}
+		}
+	}
+	return replies
+}
+
+// A short plain text preview of the comment, at most n runes long.
+// Whitespace is collapsed to single spaces and longer comments are cut at a word
+// boundary and end with an ellipsis. With stripQuotes, quote links (>>123, >>>/g/123) are dropped.
+func (p *Post) Excerpt(n int, stripQuotes bool) string {
+	words := strings.Fields(commentText(p.Comment))
+	if stripQuotes {
+		kept := words[:0]
+		for _, word := range words {
+			if !strings.HasPrefix(word, ">>") {
+				kept = append(kept, word)
+			}
+		}
+		words = kept
+	}
+	return truncateWords(strings.Join(words, " "), n)
+}
+
+// Shorten text to at most n runes, preferring to cut between words.
+// The ellipsis counts towards n.
+func truncateWords(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	if n <= 0 {
+		return ""
+	}
+	if n == 1 {
+		return string(runes[:1])
+	}
+
+	cut := n - 1
+	for i := cut; i > 0; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRight(string(runes[:cut]), " ") + "…"
+}
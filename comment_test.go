@@ -0,0 +1,78 @@
+package fourchan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCommentText(t *testing.T) {
+	tests := []struct {
+		comment, text string
+	}{
+		{"plain", "plain"},
+		{"line one<br>line two", "line one\nline two"},
+		{"<a href=\"#p123\" class=\"quotelink\">&gt;&gt;123</a><br><span class=\"quote\">&gt;be me</span>", ">>123\n>be me"},
+		{"long<wbr>word", "longword"},
+		{"a &amp; b &#039;c&#039;", "a & b 'c'"},
+		{"1 < 2", "1 < 2"},
+	}
+
+	for _, test := range tests {
+		if text := commentText(test.comment); text != test.text {
+			t.Fatalf("%q: %q != %q", test.comment, text, test.text)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	post := &Post{Comment: "<a href=\"#p1\" class=\"quotelink\">&gt;&gt;1</a><br>the quick brown fox<br><br>jumps over"}
+
+	tests := []struct {
+		n           int
+		stripQuotes bool
+		excerpt     string
+	}{
+		{100, false, ">>1 the quick brown fox jumps over"},
+		{100, true, "the quick brown fox jumps over"},
+		{16, true, "the quick brown…"},
+		{15, true, "the quick…"},
+		{4, true, "the…"},
+		{3, true, "th…"},
+	}
+
+	for _, test := range tests {
+		if excerpt := post.Excerpt(test.n, test.stripQuotes); excerpt != test.excerpt {
+			t.Fatalf("%d/%v: %q != %q", test.n, test.stripQuotes, excerpt, test.excerpt)
+		}
+	}
+}
+
+func TestQuotedPosts(t *testing.T) {
+	post := &Post{Comment: `<a href="#p1" class="quotelink">&gt;&gt;1</a><br>` +
+		`<a href="/g/thread/5#p7" class="quotelink">&gt;&gt;7</a> and <a href="#p1" class="quotelink">&gt;&gt;1</a> again<br>` +
+		`<a href="//boards.4chan.org/v/thread/9#p9" class="quotelink">&gt;&gt;&gt;/v/9</a><br>` +
+		`<span class="quote">&gt;implying</span><br>` +
+		`&gt;&gt;12&gt;&gt;34 &gt;&gt;&gt;56`}
+
+	quoted := post.QuotedPosts()
+	if fmt.Sprint(quoted) != "[1 7 12 34]" {
+		t.Fatalf("bad quotes %v", quoted)
+	}
+	if text := post.PlainText(); !strings.Contains(text, "\n>implying\n") {
+		t.Fatalf("bad text %q", text)
+	}
+}
+
+func TestReplyMap(t *testing.T) {
+	thread := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1}},
+		{Comment: "&gt;&gt;1 first", Meta: Meta{PostNumber: 2}},
+		{Comment: "&gt;&gt;1<br>&gt;&gt;2<br>&gt;&gt;99", Meta: Meta{PostNumber: 3}},
+	}}
+
+	replies := thread.ReplyMap()
+	if len(replies) != 2 || len(replies[1]) != 2 || replies[1][0] != 2 || replies[1][1] != 3 || replies[2][0] != 3 {
+		t.Fatalf("bad replies %v", replies)
+	}
+}
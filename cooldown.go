@@ -0,0 +1,119 @@
+package fourchan
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The kinds of submission boards rate limit separately.
+type SubmissionKind int
+
+const (
+	// A reply without a file.
+	SubmitReply SubmissionKind = iota
+	// A reply with a file.
+	SubmitImageReply
+	// A new thread.
+	SubmitThread
+)
+
+// Tracks our own submissions to know when each board allows the next one.
+// It is safe for concurrent use.
+type CooldownTracker struct {
+	mu     sync.Mutex
+	boards map[string]*Board
+	last   map[string]map[SubmissionKind]time.Time
+}
+
+// Create a tracker using the cooldowns of the given boards, e.g. from LoadBoards.
+func NewCooldownTracker(boards *Boards) *CooldownTracker {
+	c := &CooldownTracker{
+		boards: map[string]*Board{},
+		last:   map[string]map[SubmissionKind]time.Time{},
+	}
+	if boards != nil {
+		for i := range boards.Boards {
+			c.boards[boards.Boards[i].Name] = &boards.Boards[i]
+		}
+	}
+	return c
+}
+
+// The cooldown that applies after a submission of the given kind.
+func cooldownFor(board *Board, kind SubmissionKind) time.Duration {
+	if board == nil {
+		return 0
+	}
+	switch kind {
+	case SubmitImageReply:
+		return time.Duration(board.Cooldowns.Images) * time.Second
+	case SubmitThread:
+		return time.Duration(board.Cooldowns.Threads) * time.Second
+	}
+	return time.Duration(board.Cooldowns.Replies) * time.Second
+}
+
+// Record a successful submission.
+func (c *CooldownTracker) Record(board string, kind SubmissionKind, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last[board] == nil {
+		c.last[board] = map[SubmissionKind]time.Time{}
+	}
+	c.last[board][kind] = at
+}
+
+// When the board will next accept a submission of the given kind.
+// Any reply also starts the reply cooldown, replies with images start both.
+func (c *CooldownTracker) NextAllowedPostAt(board string, kind SubmissionKind) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b := c.boards[board]
+	var next time.Time
+	// Push next past the cooldown started by the last submission of kind submitted.
+	after := func(submitted, cooldown SubmissionKind) {
+		if last, ok := c.last[board][submitted]; ok {
+			if at := last.Add(cooldownFor(b, cooldown)); at.After(next) {
+				next = at
+			}
+		}
+	}
+
+	switch kind {
+	case SubmitThread:
+		after(SubmitThread, SubmitThread)
+	case SubmitImageReply:
+		after(SubmitImageReply, SubmitImageReply)
+		fallthrough
+	case SubmitReply:
+		after(SubmitReply, SubmitReply)
+		after(SubmitImageReply, SubmitReply)
+	}
+
+	return next
+}
+
+// Is a submission of the given kind allowed right now?
+func (c *CooldownTracker) Allowed(board string, kind SubmissionKind) bool {
+	return !time.Now().Before(c.NextAllowedPostAt(board, kind))
+}
+
+// Block until a submission of the given kind is allowed or ctx is done.
+func (c *CooldownTracker) Wait(ctx context.Context, board string, kind SubmissionKind) error {
+	wait := time.Until(c.NextAllowedPostAt(board, kind))
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
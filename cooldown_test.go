@@ -0,0 +1,54 @@
+package fourchan
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCooldownTracker(t *testing.T) {
+	boards := &Boards{Boards: []Board{{Name: "g", Cooldowns: Cooldowns{Threads: 600, Replies: 60, Images: 120}}}}
+	c := NewCooldownTracker(boards)
+	start := time.Unix(1000, 0)
+
+	if next := c.NextAllowedPostAt("g", SubmitReply); !next.IsZero() {
+		t.Fatalf("cooldown before any submission: %v", next)
+	}
+
+	c.Record("g", SubmitImageReply, start)
+	if next := c.NextAllowedPostAt("g", SubmitReply); !next.Equal(start.Add(time.Minute)) {
+		t.Fatalf("bad reply cooldown %v", next)
+	}
+	if next := c.NextAllowedPostAt("g", SubmitImageReply); !next.Equal(start.Add(2 * time.Minute)) {
+		t.Fatalf("bad image cooldown %v", next)
+	}
+	if next := c.NextAllowedPostAt("g", SubmitThread); !next.IsZero() {
+		t.Fatalf("bad thread cooldown %v", next)
+	}
+
+	c.Record("g", SubmitReply, start.Add(90*time.Second))
+	if next := c.NextAllowedPostAt("g", SubmitImageReply); !next.Equal(start.Add(150 * time.Second)) {
+		t.Fatalf("bad image cooldown after reply %v", next)
+	}
+
+	// Unknown boards have no cooldowns.
+	c.Record("b", SubmitThread, time.Now())
+	if !c.Allowed("b", SubmitThread) {
+		t.Fatal("unknown board has a cooldown")
+	}
+}
+
+func TestCooldownTrackerWait(t *testing.T) {
+	boards := &Boards{Boards: []Board{{Name: "g", Cooldowns: Cooldowns{Replies: 60}}}}
+	c := NewCooldownTracker(boards)
+	c.Record("g", SubmitReply, time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Wait(ctx, "g", SubmitReply); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := c.Wait(context.Background(), "g", SubmitThread); err != nil {
+		t.Fatal(err)
+	}
+}
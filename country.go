@@ -0,0 +1,423 @@
+package fourchan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Codes the API uses that are not ISO 3166-1 alpha-2, mapped to the ISO code.
+var countryCodeAliases = map[string]string{
+	"UK": "GB",
+	"EL": "GR",
+}
+
+// Names of the board flags /pol/ offers in place of a country.
+// These share the two letter format of the country codes and some of them
+// collide with real ISO codes (TR, CM, ...), so they must never be treated as one.
+var trollFlagNames = map[string]string{
+	"AC": "Anarcho-Capitalist",
+	"AN": "Anarchist",
+	"BL": "Black Nationalist",
+	"CF": "Confederate",
+	"CM": "Commie",
+	"CT": "Catalonia",
+	"DM": "Democrat",
+	"EU": "European",
+	"FC": "Fascist",
+	"GN": "Gadsden",
+	"GY": "LGBT",
+	"JH": "Jihadi",
+	"KN": "Kekistani",
+	"MF": "Muslim",
+	"NB": "National Bolshevik",
+	"NT": "NATO",
+	"NZ": "Nazi",
+	"PC": "Hippie",
+	"PR": "Pirate",
+	"RE": "Republican",
+	"TM": "Templar",
+	"TR": "Tree Hugger",
+	"UN": "United Nations",
+	"WP": "White Supremacist",
+}
+
+// Display names of the ISO 3166-1 countries, keyed by language and then code.
+var countryNames = map[string]map[string]string{
+	"en": englishCountryNames,
+}
+
+// Add or replace the country display names for a language.
+// Names missing from a language fall back to English.
+// This is not safe to call concurrently with lookups, do it during init.
+func RegisterCountryNames(lang string, names map[string]string) {
+	countryNames[lang] = names
+}
+
+// Normalize a country code into ISO 3166-1 alpha-2.
+// Returns false if the result is not an assigned ISO code.
+func NormalizeCountryCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if alias, ok := countryCodeAliases[code]; ok {
+		code = alias
+	}
+	_, ok := englishCountryNames[code]
+	return code, ok
+}
+
+// The display name of an ISO country code in the given language.
+// Falls back to English and then to the empty string.
+func CountryName(code, lang string) string {
+	code, ok := NormalizeCountryCode(code)
+	if !ok {
+		return ""
+	}
+	if name, ok := countryNames[lang][code]; ok {
+		return name
+	}
+	return englishCountryNames[code]
+}
+
+// The name of a /pol/ board flag code, empty if it isn't one.
+func TrollFlagName(code string) string {
+	return trollFlagNames[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// Was the post made with a /pol/ board flag instead of a country flag?
+func (m *Meta) IsTrollFlag() bool {
+	return m.TrollCountry != ""
+}
+
+// The poster's country as ISO 3166-1 alpha-2.
+// Empty when the post has a board flag, no flag at all, or an unknown code (XX, A1, ...).
+func (m *Meta) CountryISO() string {
+	if m.IsTrollFlag() {
+		return ""
+	}
+	code, ok := NormalizeCountryCode(m.CountryCode)
+	if !ok {
+		return ""
+	}
+	return code
+}
+
+// A name for the poster's flag suitable for display.
+// Countries are localized where names for lang are registered, board flags always use their English name.
+func (m *Meta) FlagName(lang string) string {
+	if m.IsTrollFlag() {
+		if name := TrollFlagName(m.TrollCountry); name != "" {
+			return name
+		}
+		return m.Country
+	}
+	if name := CountryName(m.CountryISO(), lang); name != "" {
+		return name
+	}
+	return m.Country
+}
+
+// Which kind of flag a post was made with.
+type FlagKind int
+
+const (
+	// No flag, as on boards without them. The country fields are empty rather than unknown.
+	NoFlag FlagKind = iota
+	// A country flag, which can still be one the API doesn't know (XX, A1, ...).
+	CountryFlag
+	// A board specific flag chosen in place of a country, e.g. on /pol/.
+	BoardFlag
+)
+
+// Name the kind of flag.
+func (k FlagKind) String() string {
+	switch k {
+	case NoFlag:
+		return "none"
+	case CountryFlag:
+		return "country"
+	case BoardFlag:
+		return "board"
+	}
+	return fmt.Sprintf("FlagKind(%d)", int(k))
+}
+
+// The flag a post was made with, see Meta.Flag.
+type Flag struct {
+	Kind FlagKind
+	// The ISO 3166-1 code for a known country, the code as sent for an unknown country or a board flag.
+	Code string
+	// The name as sent, or the board flag's name when the API left it out.
+	Name string
+}
+
+// The flag the post was made with, telling a board flag apart from a country
+// and both from a post without a flag.
+func (m *Meta) Flag() Flag {
+	if m.IsTrollFlag() {
+		name := m.Country
+		if name == "" {
+			name = TrollFlagName(m.TrollCountry)
+		}
+		return Flag{Kind: BoardFlag, Code: m.TrollCountry, Name: name}
+	}
+	if m.CountryCode == "" {
+		return Flag{Kind: NoFlag}
+	}
+	code := m.CountryISO()
+	if code == "" {
+		code = m.CountryCode
+	}
+	return Flag{Kind: CountryFlag, Code: code, Name: m.Country}
+}
+
+// English names of the ISO 3166-1 countries.
+var englishCountryNames = map[string]string{
+	"AD": "Andorra",
+	"AE": "United Arab Emirates",
+	"AF": "Afghanistan",
+	"AG": "Antigua and Barbuda",
+	"AI": "Anguilla",
+	"AL": "Albania",
+	"AM": "Armenia",
+	"AO": "Angola",
+	"AQ": "Antarctica",
+	"AR": "Argentina",
+	"AS": "American Samoa",
+	"AT": "Austria",
+	"AU": "Australia",
+	"AW": "Aruba",
+	"AX": "Åland Islands",
+	"AZ": "Azerbaijan",
+	"BA": "Bosnia and Herzegovina",
+	"BB": "Barbados",
+	"BD": "Bangladesh",
+	"BE": "Belgium",
+	"BF": "Burkina Faso",
+	"BG": "Bulgaria",
+	"BH": "Bahrain",
+	"BI": "Burundi",
+	"BJ": "Benin",
+	"BL": "Saint Barthélemy",
+	"BM": "Bermuda",
+	"BN": "Brunei Darussalam",
+	"BO": "Bolivia",
+	"BQ": "Bonaire, Sint Eustatius and Saba",
+	"BR": "Brazil",
+	"BS": "Bahamas",
+	"BT": "Bhutan",
+	"BV": "Bouvet Island",
+	"BW": "Botswana",
+	"BY": "Belarus",
+	"BZ": "Belize",
+	"CA": "Canada",
+	"CC": "Cocos (Keeling) Islands",
+	"CD": "Congo, The Democratic Republic of the",
+	"CF": "Central African Republic",
+	"CG": "Congo",
+	"CH": "Switzerland",
+	"CI": "Côte d'Ivoire",
+	"CK": "Cook Islands",
+	"CL": "Chile",
+	"CM": "Cameroon",
+	"CN": "China",
+	"CO": "Colombia",
+	"CR": "Costa Rica",
+	"CU": "Cuba",
+	"CV": "Cabo Verde",
+	"CW": "Curaçao",
+	"CX": "Christmas Island",
+	"CY": "Cyprus",
+	"CZ": "Czechia",
+	"DE": "Germany",
+	"DJ": "Djibouti",
+	"DK": "Denmark",
+	"DM": "Dominica",
+	"DO": "Dominican Republic",
+	"DZ": "Algeria",
+	"EC": "Ecuador",
+	"EE": "Estonia",
+	"EG": "Egypt",
+	"EH": "Western Sahara",
+	"ER": "Eritrea",
+	"ES": "Spain",
+	"ET": "Ethiopia",
+	"FI": "Finland",
+	"FJ": "Fiji",
+	"FK": "Falkland Islands (Malvinas)",
+	"FM": "Micronesia, Federated States of",
+	"FO": "Faroe Islands",
+	"FR": "France",
+	"GA": "Gabon",
+	"GB": "United Kingdom",
+	"GD": "Grenada",
+	"GE": "Georgia",
+	"GF": "French Guiana",
+	"GG": "Guernsey",
+	"GH": "Ghana",
+	"GI": "Gibraltar",
+	"GL": "Greenland",
+	"GM": "Gambia",
+	"GN": "Guinea",
+	"GP": "Guadeloupe",
+	"GQ": "Equatorial Guinea",
+	"GR": "Greece",
+	"GS": "South Georgia and the South Sandwich Islands",
+	"GT": "Guatemala",
+	"GU": "Guam",
+	"GW": "Guinea-Bissau",
+	"GY": "Guyana",
+	"HK": "Hong Kong",
+	"HM": "Heard Island and McDonald Islands",
+	"HN": "Honduras",
+	"HR": "Croatia",
+	"HT": "Haiti",
+	"HU": "Hungary",
+	"ID": "Indonesia",
+	"IE": "Ireland",
+	"IL": "Israel",
+	"IM": "Isle of Man",
+	"IN": "India",
+	"IO": "British Indian Ocean Territory",
+	"IQ": "Iraq",
+	"IR": "Iran",
+	"IS": "Iceland",
+	"IT": "Italy",
+	"JE": "Jersey",
+	"JM": "Jamaica",
+	"JO": "Jordan",
+	"JP": "Japan",
+	"KE": "Kenya",
+	"KG": "Kyrgyzstan",
+	"KH": "Cambodia",
+	"KI": "Kiribati",
+	"KM": "Comoros",
+	"KN": "Saint Kitts and Nevis",
+	"KP": "North Korea",
+	"KR": "South Korea",
+	"KW": "Kuwait",
+	"KY": "Cayman Islands",
+	"KZ": "Kazakhstan",
+	"LA": "Laos",
+	"LB": "Lebanon",
+	"LC": "Saint Lucia",
+	"LI": "Liechtenstein",
+	"LK": "Sri Lanka",
+	"LR": "Liberia",
+	"LS": "Lesotho",
+	"LT": "Lithuania",
+	"LU": "Luxembourg",
+	"LV": "Latvia",
+	"LY": "Libya",
+	"MA": "Morocco",
+	"MC": "Monaco",
+	"MD": "Moldova",
+	"ME": "Montenegro",
+	"MF": "Saint Martin (French part)",
+	"MG": "Madagascar",
+	"MH": "Marshall Islands",
+	"MK": "North Macedonia",
+	"ML": "Mali",
+	"MM": "Myanmar",
+	"MN": "Mongolia",
+	"MO": "Macao",
+	"MP": "Northern Mariana Islands",
+	"MQ": "Martinique",
+	"MR": "Mauritania",
+	"MS": "Montserrat",
+	"MT": "Malta",
+	"MU": "Mauritius",
+	"MV": "Maldives",
+	"MW": "Malawi",
+	"MX": "Mexico",
+	"MY": "Malaysia",
+	"MZ": "Mozambique",
+	"NA": "Namibia",
+	"NC": "New Caledonia",
+	"NE": "Niger",
+	"NF": "Norfolk Island",
+	"NG": "Nigeria",
+	"NI": "Nicaragua",
+	"NL": "Netherlands",
+	"NO": "Norway",
+	"NP": "Nepal",
+	"NR": "Nauru",
+	"NU": "Niue",
+	"NZ": "New Zealand",
+	"OM": "Oman",
+	"PA": "Panama",
+	"PE": "Peru",
+	"PF": "French Polynesia",
+	"PG": "Papua New Guinea",
+	"PH": "Philippines",
+	"PK": "Pakistan",
+	"PL": "Poland",
+	"PM": "Saint Pierre and Miquelon",
+	"PN": "Pitcairn",
+	"PR": "Puerto Rico",
+	"PS": "Palestine, State of",
+	"PT": "Portugal",
+	"PW": "Palau",
+	"PY": "Paraguay",
+	"QA": "Qatar",
+	"RE": "Réunion",
+	"RO": "Romania",
+	"RS": "Serbia",
+	"RU": "Russian Federation",
+	"RW": "Rwanda",
+	"SA": "Saudi Arabia",
+	"SB": "Solomon Islands",
+	"SC": "Seychelles",
+	"SD": "Sudan",
+	"SE": "Sweden",
+	"SG": "Singapore",
+	"SH": "Saint Helena, Ascension and Tristan da Cunha",
+	"SI": "Slovenia",
+	"SJ": "Svalbard and Jan Mayen",
+	"SK": "Slovakia",
+	"SL": "Sierra Leone",
+	"SM": "San Marino",
+	"SN": "Senegal",
+	"SO": "Somalia",
+	"SR": "Suriname",
+	"SS": "South Sudan",
+	"ST": "Sao Tome and Principe",
+	"SV": "El Salvador",
+	"SX": "Sint Maarten (Dutch part)",
+	"SY": "Syria",
+	"SZ": "Eswatini",
+	"TC": "Turks and Caicos Islands",
+	"TD": "Chad",
+	"TF": "French Southern Territories",
+	"TG": "Togo",
+	"TH": "Thailand",
+	"TJ": "Tajikistan",
+	"TK": "Tokelau",
+	"TL": "Timor-Leste",
+	"TM": "Turkmenistan",
+	"TN": "Tunisia",
+	"TO": "Tonga",
+	"TR": "Türkiye",
+	"TT": "Trinidad and Tobago",
+	"TV": "Tuvalu",
+	"TW": "Taiwan",
+	"TZ": "Tanzania",
+	"UA": "Ukraine",
+	"UG": "Uganda",
+	"UM": "United States Minor Outlying Islands",
+	"US": "United States",
+	"UY": "Uruguay",
+	"UZ": "Uzbekistan",
+	"VA": "Holy See (Vatican City State)",
+	"VC": "Saint Vincent and the Grenadines",
+	"VE": "Venezuela",
+	"VG": "Virgin Islands, British",
+	"VI": "Virgin Islands, U.S.",
+	"VN": "Vietnam",
+	"VU": "Vanuatu",
+	"WF": "Wallis and Futuna",
+	"WS": "Samoa",
+	"YE": "Yemen",
+	"YT": "Mayotte",
+	"ZA": "South Africa",
+	"ZM": "Zambia",
+	"ZW": "Zimbabwe",
+}
@@ -0,0 +1,79 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestNormalizeCountryCode(t *testing.T) {
+	tests := []struct {
+		in, out string
+		ok      bool
+	}{
+		{"US", "US", true},
+		{" de", "DE", true},
+		{"UK", "GB", true},
+		{"XX", "XX", false},
+	}
+
+	for _, test := range tests {
+		code, ok := NormalizeCountryCode(test.in)
+		if code != test.out || ok != test.ok {
+			t.Fatalf("%q: %q/%v != %q/%v", test.in, code, ok, test.out, test.ok)
+		}
+	}
+}
+
+func TestCountryISO(t *testing.T) {
+	tests := []struct {
+		meta      Meta
+		iso, name string
+	}{
+		{Meta{CountryCode: "FI", Country: "Finland"}, "FI", "Finland"},
+		{Meta{CountryCode: "XX", Country: "Unknown"}, "", "Unknown"},
+		{Meta{TrollCountry: "TR", Country: "Tree Hugger"}, "", "Tree Hugger"},
+		{Meta{}, "", ""},
+	}
+
+	for _, test := range tests {
+		if iso := test.meta.CountryISO(); iso != test.iso {
+			t.Fatalf("%+v: %q != %q", test.meta, iso, test.iso)
+		}
+		if name := test.meta.FlagName("en"); name != test.name {
+			t.Fatalf("%+v: %q != %q", test.meta, name, test.name)
+		}
+	}
+}
+
+func TestFlag(t *testing.T) {
+	tests := []struct {
+		meta Meta
+		flag Flag
+	}{
+		{Meta{CountryCode: "UK", Country: "United Kingdom"}, Flag{CountryFlag, "GB", "United Kingdom"}},
+		{Meta{CountryCode: "XX", Country: "Unknown"}, Flag{CountryFlag, "XX", "Unknown"}},
+		{Meta{CountryCode: "TR", TrollCountry: "TR", Country: "Tree Hugger"}, Flag{BoardFlag, "TR", "Tree Hugger"}},
+		{Meta{TrollCountry: "NZ"}, Flag{BoardFlag, "NZ", "Nazi"}},
+		{Meta{}, Flag{Kind: NoFlag}},
+	}
+
+	for _, test := range tests {
+		if flag := test.meta.Flag(); flag != test.flag {
+			t.Fatalf("%+v: %+v != %+v", test.meta, flag, test.flag)
+		}
+	}
+	if BoardFlag.String() != "board" || FlagKind(9).String() != "FlagKind(9)" {
+		t.Fatal("bad flag kind names")
+	}
+}
+
+func TestCountryNameFallsBack(t *testing.T) {
+	RegisterCountryNames("de", map[string]string{"DE": "Deutschland"})
+	defer delete(countryNames, "de")
+
+	if name := CountryName("de", "de"); name != "Deutschland" {
+		t.Fatalf("%q != Deutschland", name)
+	}
+	if name := CountryName("FR", "de"); name != "France" {
+		t.Fatalf("%q != France", name)
+	}
+}
@@ -0,0 +1,172 @@
+package fourchan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A change to one of a thread's flags.
+type FlagChange struct {
+	// Which flag changed: "closed", "archived" or "sticky".
+	Flag string `json:"flag"`
+	Old  bool   `json:"old"`
+	New  bool   `json:"new"`
+}
+
+// A count before and after.
+type CountChange struct {
+	Old int `json:"old"`
+	New int `json:"new"`
+}
+
+// What changed between two snapshots of a thread.
+// It marshals to JSON as is, and String gives a one line summary for logs.
+type ThreadDiff struct {
+	// The thread compared.
+	Thread ThreadRef `json:"thread"`
+	// Posts that weren't in the old snapshot.
+	Added []Post `json:"added"`
+	// Posts that are no longer in the thread.
+	// Replies a rolling sticky dropped to make room aren't counted, they are in RolledOff.
+	Removed []Post `json:"removed"`
+	// Replies a rolling sticky dropped to make room for new ones, older than every reply it still has.
+	RolledOff []Post `json:"rolled_off"`
+	// Posts that are still there but have had their files deleted.
+	FileDeleted []Post `json:"file_deleted"`
+	// Changes to the OP's flags.
+	Flags []FlagChange `json:"flags"`
+	// The reply and image counts the OP reports.
+	Replies CountChange `json:"replies"`
+	Images  CountChange `json:"images"`
+}
+
+// Did anything change?
+func (d *ThreadDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.RolledOff) == 0 && len(d.FileDeleted) == 0 && len(d.Flags) == 0
+}
+
+// Summarize the changes, e.g. "/g/123: 2 added, 1 removed, archived, 40 -> 42 replies".
+func (d *ThreadDiff) String() string {
+	var changes []string
+	count := func(n int, what string) {
+		if n > 0 {
+			changes = append(changes, fmt.Sprintf("%d %s", n, what))
+		}
+	}
+	count(len(d.Added), "added")
+	count(len(d.Removed), "removed")
+	count(len(d.RolledOff), "rolled off")
+	count(len(d.FileDeleted), "files deleted")
+	for _, flag := range d.Flags {
+		if flag.New {
+			changes = append(changes, flag.Flag)
+		} else {
+			changes = append(changes, "no longer "+flag.Flag)
+		}
+	}
+	if d.Replies.Old != d.Replies.New {
+		changes = append(changes, fmt.Sprintf("%d -> %d replies", d.Replies.Old, d.Replies.New))
+	}
+	if d.Images.Old != d.Images.New {
+		changes = append(changes, fmt.Sprintf("%d -> %d images", d.Images.Old, d.Images.New))
+	}
+
+	if len(changes) == 0 {
+		return d.Thread.String() + ": no changes"
+	}
+	return d.Thread.String() + ": " + strings.Join(changes, ", ")
+}
+
+// Compare the thread with an older snapshot of it.
+// A nil old snapshot reports every post as added.
+func (t *Thread) Diff(old *Thread) *ThreadDiff {
+	diff := &ThreadDiff{Thread: t.Ref()}
+	if len(t.Posts) > 0 {
+		diff.Replies.New = t.Posts[0].ReplyCount
+		diff.Images.New = t.Posts[0].ImageCount
+	}
+	if old == nil {
+		// A copy, so changing the diff's posts doesn't change the thread.
+		diff.Added = append([]Post(nil), t.Posts...)
+		return diff
+	}
+	if len(old.Posts) > 0 {
+		diff.Replies.Old = old.Posts[0].ReplyCount
+		diff.Images.Old = old.Posts[0].ImageCount
+	}
+
+	before := map[uint64]*Post{}
+	for i := range old.Posts {
+		before[old.Posts[i].PostNumber] = &old.Posts[i]
+	}
+	for i := range t.Posts {
+		post := &t.Posts[i]
+		was, ok := before[post.PostNumber]
+		if !ok {
+			diff.Added = append(diff.Added, *post)
+		} else if post.FileDeleted && !was.FileDeleted {
+			diff.FileDeleted = append(diff.FileDeleted, *post)
+		}
+	}
+	diff.Removed, diff.RolledOff = deletedPosts(old, t)
+
+	if len(old.Posts) > 0 && len(t.Posts) > 0 {
+		was, now := &old.Posts[0].Meta, &t.Posts[0].Meta
+		flag := func(name string, old, new bool) {
+			if old != new {
+				diff.Flags = append(diff.Flags, FlagChange{name, old, new})
+			}
+		}
+		flag("closed", was.Closed, now.Closed)
+		flag("archived", was.Archived, now.Archived)
+		flag("sticky", was.Sticky, now.Sticky)
+	}
+
+	return diff
+}
+
+// Add the replies a rolling sticky dropped since old back into the thread, so an archive of it keeps them.
+// Returns the thread itself when nothing rolled off, or when it is immutable, as it then already is
+// what its archive kept. Merging into an archive that already holds earlier rolled off replies keeps those as well.
+func (t *Thread) KeepRolledOff(old *Thread) *Thread {
+	if old == nil || len(t.Posts) == 0 || t.IsImmutable() {
+		return t
+	}
+	_, rolledOff := deletedPosts(old, t)
+	if len(rolledOff) == 0 {
+		return t
+	}
+	merged := *t
+	merged.Posts = make([]Post, 0, len(t.Posts)+len(rolledOff))
+	merged.Posts = append(merged.Posts, t.Posts[0])
+	merged.Posts = append(merged.Posts, rolledOff...)
+	merged.Posts = append(merged.Posts, t.Posts[1:]...)
+	return &merged
+}
+
+// The posts in old that are missing from updated.
+// Rolling stickies drop their oldest replies, so those are returned apart from the deleted ones.
+func deletedPosts(old, updated *Thread) (deleted, rolledOff []Post) {
+	present := map[uint64]bool{}
+	for i := range updated.Posts {
+		present[updated.Posts[i].PostNumber] = true
+	}
+
+	var oldest uint64
+	if updated.IsRollingSticky() && len(updated.Posts) > 1 {
+		oldest = updated.Posts[1].PostNumber
+	}
+
+	for i := range old.Posts {
+		post := &old.Posts[i]
+		if present[post.PostNumber] {
+			continue
+		}
+		if post.PostNumber < oldest {
+			rolledOff = append(rolledOff, *post)
+		} else {
+			deleted = append(deleted, *post)
+		}
+	}
+	return deleted, rolledOff
+}
@@ -0,0 +1,117 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestThreadDiff(t *testing.T) {
+	old := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1}},
+		{Meta: Meta{PostNumber: 2, HasFile: true}},
+		{Meta: Meta{PostNumber: 3}},
+	}}
+	updated := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1, Closed: true, Archived: true}},
+		{Meta: Meta{PostNumber: 2, HasFile: true, FileDeleted: true}},
+		{Meta: Meta{PostNumber: 4}},
+	}}
+
+	diff := updated.Diff(old)
+	if len(diff.Added) != 1 || diff.Added[0].PostNumber != 4 {
+		t.Fatalf("bad added %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].PostNumber != 3 {
+		t.Fatalf("bad removed %v", diff.Removed)
+	}
+	if len(diff.FileDeleted) != 1 || diff.FileDeleted[0].PostNumber != 2 {
+		t.Fatalf("bad file deleted %v", diff.FileDeleted)
+	}
+	if len(diff.Flags) != 2 || diff.Flags[0] != (FlagChange{"closed", false, true}) || diff.Flags[1].Flag != "archived" {
+		t.Fatalf("bad flags %v", diff.Flags)
+	}
+
+	if !updated.Diff(updated).Empty() {
+		t.Fatal("thread differs from itself")
+	}
+	if diff = updated.Diff(nil); len(diff.Added) != 3 {
+		t.Fatalf("bad diff against nothing %+v", diff)
+	}
+	if diff.Added[0].Comment = "changed"; updated.Posts[0].Comment == "changed" {
+		t.Fatal("diff shares its posts with the thread")
+	}
+}
+
+func TestThreadDiffRollingSticky(t *testing.T) {
+	old := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1, Sticky: true, StickyCap: 2}},
+		{Meta: Meta{PostNumber: 2}},
+		{Meta: Meta{PostNumber: 3}},
+	}}
+	updated := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1, Sticky: true, StickyCap: 2}},
+		{Meta: Meta{PostNumber: 3}},
+		{Meta: Meta{PostNumber: 4}},
+	}}
+
+	diff := updated.Diff(old)
+	if len(diff.Removed) != 0 || len(diff.Added) != 1 {
+		t.Fatalf("dropped replies counted as removed %+v", diff)
+	}
+	if len(diff.RolledOff) != 1 || diff.RolledOff[0].PostNumber != 2 || !strings.Contains(diff.String(), "1 rolled off") {
+		t.Fatalf("bad rolled off replies %+v", diff)
+	}
+
+	archive := updated.KeepRolledOff(old)
+	if len(archive.Posts) != 4 || archive.Posts[1].PostNumber != 2 || len(updated.Posts) != 3 {
+		t.Fatalf("rolled off replies not kept %+v", archive.Posts)
+	}
+	newer := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1, Sticky: true, StickyCap: 2}},
+		{Meta: Meta{PostNumber: 4}},
+		{Meta: Meta{PostNumber: 5}},
+	}}
+	if archive = newer.KeepRolledOff(archive); len(archive.Posts) != 5 || archive.Posts[1].PostNumber != 2 || archive.Posts[2].PostNumber != 3 {
+		t.Fatalf("earlier rolled off replies not kept %+v", archive.Posts)
+	}
+	if updated.KeepRolledOff(updated) != updated {
+		t.Fatal("thread copied with nothing rolled off")
+	}
+}
+
+func TestThreadDiffReport(t *testing.T) {
+	old := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1, ReplyCount: 1}}, {Meta: Meta{PostNumber: 2}}}}
+	updated := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, ReplyCount: 2, ImageCount: 1, Closed: true}},
+		{Meta: Meta{PostNumber: 2}},
+		{Meta: Meta{PostNumber: 3, HasFile: true}},
+	}}
+
+	diff := updated.Diff(old)
+	if diff.Thread != (ThreadRef{"g", 1}) || diff.Replies != (CountChange{1, 2}) || diff.Images != (CountChange{0, 1}) {
+		t.Fatalf("bad report %+v", diff)
+	}
+	if s := diff.String(); s != "/g/1: 1 added, closed, 1 -> 2 replies, 0 -> 1 images" {
+		t.Fatal(s)
+	}
+	if s := updated.Diff(updated).String(); s != "/g/1: no changes" {
+		t.Fatal(s)
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Thread ThreadRef
+		Added  []Post
+		Flags  []FlagChange
+	}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Thread != diff.Thread || len(decoded.Added) != 1 || decoded.Added[0].PostNumber != 3 || decoded.Flags[0] != diff.Flags[0] {
+		t.Fatalf("bad JSON %s", data)
+	}
+}
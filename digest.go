@@ -0,0 +1,217 @@
+package fourchan
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// Options for building a digest.
+type DigestOptions struct {
+	// Posts containing any of these words, ignoring case and accents, are listed as notable.
+	Keywords []string
+	// Posts matching this filter are listed as notable too.
+	Notable *Filter
+	// Advance the tracker past every post included in the digest.
+	MarkRead bool
+	// Longest excerpt shown for a notable post, defaults to 200 runes.
+	ExcerptLength int
+	// Link to files on this client's media host, see WithMediaURL. Defaults to DefaultClient.
+	Client *Client
+}
+
+// The new activity in one thread.
+type ThreadDigest struct {
+	Board string
+	// The post number of the OP.
+	Thread  uint64
+	Subject string
+	// Number of posts since the last digest.
+	NewPosts int
+	// New posts with files.
+	NewImages []Post
+	// New posts matching the keywords or filter.
+	Notable []Post
+}
+
+// A summary of new activity across a set of threads.
+type Digest struct {
+	// When the digest was built.
+	Generated time.Time
+	// Threads with new posts, in the order they were given.
+	Threads []ThreadDigest
+
+	excerptLength int
+	client        *Client
+}
+
+// Summarize what is new in the given threads since the tracker last marked them read.
+// A nil tracker treats every post as new.
+// Run it from whatever schedule suits, e.g. a ticker over a watch list.
+func BuildDigest(threads []*Thread, tracker *ReadTracker, opts *DigestOptions) (*Digest, error) {
+	if opts == nil {
+		opts = &DigestOptions{}
+	}
+	digest := &Digest{Generated: time.Now(), excerptLength: opts.ExcerptLength, client: opts.Client}
+	if digest.excerptLength <= 0 {
+		digest.excerptLength = 200
+	}
+
+	keywords := make([]string, len(opts.Keywords))
+	for i, keyword := range opts.Keywords {
+		keywords[i] = FoldText(keyword)
+	}
+
+	for _, thread := range threads {
+		if len(thread.Posts) == 0 {
+			continue
+		}
+
+		posts := thread.Posts
+		if tracker != nil {
+			var err error
+			if posts, err = tracker.Unread(thread); err != nil {
+				return nil, err
+			}
+		}
+		if len(posts) == 0 {
+			continue
+		}
+
+		td := ThreadDigest{
+			Board:    thread.Board,
+			Thread:   thread.Posts[0].PostNumber,
+			Subject:  threadTitle(thread),
+			NewPosts: len(posts),
+		}
+		for i := range posts {
+			post := &posts[i]
+			if post.HasFile && !post.FileDeleted {
+				td.NewImages = append(td.NewImages, *post)
+			}
+			if isNotable(post, keywords, opts.Notable) {
+				td.Notable = append(td.Notable, *post)
+			}
+		}
+		digest.Threads = append(digest.Threads, td)
+
+		if tracker != nil && opts.MarkRead {
+			if err := tracker.MarkRead(thread); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return digest, nil
+}
+
+func isNotable(post *Post, keywords []string, filter *Filter) bool {
+	if filter != nil && filter.Match(post) {
+		return true
+	}
+	if len(keywords) == 0 {
+		return false
+	}
+	text := FoldText(commentText(post.Subject) + "\n" + commentText(post.Comment))
+	for _, keyword := range keywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Total new posts across every thread.
+func (d *Digest) NewPosts() int {
+	n := 0
+	for _, td := range d.Threads {
+		n += td.NewPosts
+	}
+	return n
+}
+
+// The client whose media host files are linked on.
+func (d *Digest) mediaClient() *Client {
+	if d.client == nil {
+		return defaultClient()
+	}
+	return d.client
+}
+
+// Render the digest as Markdown.
+func (d *Digest) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Digest for %s\n\n", d.Generated.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "%d new posts in %d threads.\n", d.NewPosts(), len(d.Threads))
+
+	for _, td := range d.Threads {
+		fmt.Fprintf(&b, "\n## [/%s/ - %s](%s)\n\n", td.Board, markdownText(td.Subject), postURL(td.Board, td.Thread, td.Thread))
+		fmt.Fprintf(&b, "%d new posts, %d new images.\n", td.NewPosts, len(td.NewImages))
+
+		if len(td.Notable) > 0 {
+			b.WriteString("\n")
+			for i := range td.Notable {
+				post := &td.Notable[i]
+				fmt.Fprintf(&b, "- [No.%d](%s): %s\n", post.PostNumber,
+					postURL(td.Board, td.Thread, post.PostNumber), markdownText(post.Excerpt(d.excerptLength, false)))
+			}
+		}
+		if len(td.NewImages) > 0 {
+			b.WriteString("\n")
+			for i := range td.NewImages {
+				post := &td.NewImages[i]
+				fmt.Fprintf(&b, "- [%s](%s)\n", markdownText(post.FileName()), d.mediaClient().ImageURL(td.Board, post))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Escapes what would break out of a Markdown link's text, or start one.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`)
+
+// Escape text for use in Markdown, so it can't end a link early or make one of its own.
+func markdownText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// Render the digest as a standalone HTML page, e.g. for email.
+func (d *Digest) WriteHTML(w io.Writer) error {
+	var b strings.Builder
+	title := html.EscapeString("Digest for " + d.Generated.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>%s</title></head>\n<body>\n", title)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<p>%d new posts in %d threads.</p>\n", title, d.NewPosts(), len(d.Threads))
+
+	for _, td := range d.Threads {
+		fmt.Fprintf(&b, "<h2><a href=\"%s\">/%s/ - %s</a></h2>\n", postURL(td.Board, td.Thread, td.Thread),
+			html.EscapeString(td.Board), html.EscapeString(td.Subject))
+		fmt.Fprintf(&b, "<p>%d new posts, %d new images.</p>\n", td.NewPosts, len(td.NewImages))
+
+		if len(td.Notable) > 0 {
+			b.WriteString("<ul>\n")
+			for i := range td.Notable {
+				post := &td.Notable[i]
+				fmt.Fprintf(&b, "<li><a href=\"%s\">No.%d</a>: %s</li>\n", postURL(td.Board, td.Thread, post.PostNumber),
+					post.PostNumber, html.EscapeString(post.Excerpt(d.excerptLength, false)))
+			}
+			b.WriteString("</ul>\n")
+		}
+		if len(td.NewImages) > 0 {
+			b.WriteString("<p>\n")
+			for i := range td.NewImages {
+				post := &td.NewImages[i]
+				fmt.Fprintf(&b, "<a href=\"%s\"><img src=\"%s\" alt=\"%s\"></a>\n", d.mediaClient().ImageURL(td.Board, post),
+					d.mediaClient().ThumbnailURL(td.Board, post), html.EscapeString(post.FileName()))
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
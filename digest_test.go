@@ -0,0 +1,70 @@
+package fourchan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBuildDigest(t *testing.T) {
+	tracker := &ReadTracker{Store: NewMemoryReadStore(), Consumer: "mail"}
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Subject: "/hpg/", Meta: Meta{PostNumber: 1}},
+		{Comment: "Anyone tried the new Sennheiser?", Meta: Meta{PostNumber: 2}},
+		{Comment: "pic related", Meta: Meta{PostNumber: 3, HasFile: true, RenamedFileName: 123, FileExt: ".jpg", OrigFileName: "cans"}},
+	}}
+	quiet := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 10}}}}
+	tracker.MarkRead(quiet)
+
+	opts := &DigestOptions{Keywords: []string{"sennheiser"}, MarkRead: true}
+	digest, err := BuildDigest([]*Thread{thread, quiet}, tracker, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(digest.Threads) != 1 || digest.NewPosts() != 3 {
+		t.Fatalf("bad digest %+v", digest)
+	}
+	td := digest.Threads[0]
+	if td.Subject != "/hpg/" || len(td.NewImages) != 1 || len(td.Notable) != 1 || td.Notable[0].PostNumber != 2 {
+		t.Fatalf("bad thread digest %+v", td)
+	}
+
+	var md, page bytes.Buffer
+	if err = digest.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "[No.2](https://boards.4chan.org/g/thread/1#p2): Anyone tried the new Sennheiser?") {
+		t.Fatalf("bad markdown:\n%s", md.String())
+	}
+	if !strings.Contains(md.String(), "[cans.jpg](https://i.4cdn.org/g/123.jpg)") {
+		t.Fatalf("bad markdown:\n%s", md.String())
+	}
+	if err = digest.WriteHTML(&page); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.String(), `<img src="https://i.4cdn.org/g/123s.jpg" alt="cans.jpg">`) {
+		t.Fatalf("bad html:\n%s", page.String())
+	}
+
+	// Everything was marked read.
+	digest, err = BuildDigest([]*Thread{thread, quiet}, tracker, opts)
+	if err != nil || len(digest.Threads) != 0 {
+		t.Fatalf("digest not empty: %+v %v", digest, err)
+	}
+
+	odd := &Thread{Board: "g", Posts: []Post{
+		{Subject: `[a](b)`, Meta: Meta{PostNumber: 20, HasFile: true, RenamedFileName: 124, FileExt: ".jpg", OrigFileName: `c\d]`}},
+	}}
+	digest, err = BuildDigest([]*Thread{odd}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md.Reset()
+	if err = digest.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), `## [/g/ - \[a\]\(b\)](`) || !strings.Contains(md.String(), `- [c\\d\].jpg](`) {
+		t.Fatalf("markdown not escaped:\n%s", md.String())
+	}
+}
@@ -0,0 +1,327 @@
+package fourchan
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Options for downloading the files in a thread.
+type DownloadOptions struct {
+	// Most downloads in flight at once, defaults to 4. They still share the client's rate limit.
+	Concurrency int
+	// Leave files already in the directory alone.
+	SkipExisting bool
+	// Along with SkipExisting, check existing files against the API's MD5 and download them again if they differ.
+	VerifyExisting bool
+	// Only download files passing this filter, nil downloads every file.
+	Filter *MediaFilter
+	// Download thumbnails too, saved next to the files as 1456789012345s.jpg.
+	Thumbnails bool
+	// Download only the thumbnails, for a visual record of a board at a fraction of the bandwidth and space.
+	// What there is to know about the files themselves, their names, sizes, dimensions and MD5s, is in the
+	// thread, so save it with Thread.Save next to the thumbnails.
+	ThumbnailsOnly bool
+	// The client to download with, defaults to DefaultClient.
+	Client *Client
+	// Flush each file to disk before it is put in place, so a crash can't leave a truncated file behind.
+	// This is slower, and a crash then only leaves temporary files, which CleanPartialFiles removes.
+	Sync bool
+	// Name files after what the poster called them, e.g. 1456789012345 desk.jpg rather than 1456789012345.jpg.
+	// The renamed name stays in front so names are unique, and the rest is sanitized with SanitizeFileName.
+	// MediaServer can't find files named this way.
+	OriginalNames bool
+	// How to sanitize original names, nil uses the defaults.
+	Sanitize *SanitizeOptions
+}
+
+// The name to give a post's file in the download directory.
+func (opts *DownloadOptions) fileName(post *Post) string {
+	if !opts.OriginalNames || post.OrigFileName == "" {
+		return post.mediaName()
+	}
+	return SanitizeFileName(strconv.FormatUint(post.RenamedFileName, 10)+" "+post.OrigFileName+post.FileExt, opts.Sanitize)
+}
+
+// Download the post's file from the given board into w and check it against the post's MD5.
+// Returns ChecksumMismatchError when the file doesn't match, by which point w has had every byte.
+func (p *Post) DownloadImage(ctx context.Context, board string, w io.Writer) error {
+	return defaultClient().DownloadImage(ctx, board, p, w)
+}
+
+// Download the post's file from the given board into w and check it against the post's MD5.
+// Returns ChecksumMismatchError when the file doesn't match, by which point w has had every byte.
+func (c *Client) DownloadImage(ctx context.Context, board string, post *Post, w io.Writer) error {
+	if !post.HasFile || post.FileDeleted {
+		return fmt.Errorf("post %d has no file", post.PostNumber)
+	}
+
+	sum, err := c.download(ctx, c.ImageURL(board, post), w)
+	if err != nil {
+		return err
+	}
+	if post.FileMD5 == "" {
+		return nil
+	}
+	return post.checkMD5(sum)
+}
+
+// Download the thumbnail of the post's file from the given board into w.
+func (c *Client) DownloadThumbnail(ctx context.Context, board string, post *Post, w io.Writer) error {
+	if QuirksFor(board).NoThumbnails {
+		return fmt.Errorf("/%s/ has no thumbnails", board)
+	}
+	_, err := c.download(ctx, c.ThumbnailURL(board, post), w)
+	return err
+}
+
+// Check whether the media host still has a file, see Client.FileExists.
+func FileExists(board string, tim uint64, ext string) (bool, error) {
+	return defaultClient().FileExists(context.Background(), board, tim, ext)
+}
+
+// Check whether the media host still has the file with the given renamed name and extension, e.g.
+// 1456789012345 and ".webm", with a HEAD request, so nothing is downloaded.
+// Returns false for a file that's gone, and an error when the host said something else.
+func (c *Client) FileExists(ctx context.Context, board string, tim uint64, ext string) (bool, error) {
+	url := fmt.Sprintf("%s/%s/%d%s", c.mediaURL, board, tim, ext)
+	req, err := http.NewRequest("HEAD", url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, responseError(url, resp)
+}
+
+// Copy the body at url into w, returning its MD5.
+func (c *Client) download(ctx context.Context, url string, w io.Writer) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(url, resp)
+	}
+
+	h := md5.New()
+	if _, err = io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// A file a download would write.
+type PlannedFile struct {
+	// The file's name in the download directory.
+	Name string
+	// The post the file is attached to.
+	Post *Post
+	// Is this the post's thumbnail rather than its file?
+	Thumbnail bool
+	// The size the API reports, 0 for thumbnails as it doesn't say.
+	Size int
+}
+
+// What downloading a thread's files would do.
+type DownloadPlan struct {
+	// The files that would be downloaded, in thread order.
+	Files []PlannedFile
+	// How many files are already there and would be skipped.
+	Skipped int
+	// The total size of Files, not counting thumbnails or files of UnknownSize.
+	Bytes int64
+}
+
+// Work out what DownloadAllImages would download into dir with the same options, without downloading or writing anything.
+// This is a dry run for checking filters and the like before spending the bandwidth.
+func (t *Thread) PlanDownloads(dir string, opts *DownloadOptions) *DownloadPlan {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	plan := &DownloadPlan{}
+	add := func(file PlannedFile, exists func(path string) bool) {
+		if opts.SkipExisting && exists(filepath.Join(dir, file.Name)) {
+			plan.Skipped++
+			return
+		}
+		plan.Files = append(plan.Files, file)
+		if file.Size > 0 {
+			plan.Bytes += int64(file.Size)
+		}
+	}
+
+	for i := range t.Posts {
+		post := &t.Posts[i]
+		if !opts.Filter.Match(post) {
+			continue
+		}
+
+		if !opts.ThumbnailsOnly {
+			add(PlannedFile{Name: opts.fileName(post), Post: post, Size: post.FileSize},
+				func(path string) bool { return existingFileOK(path, post, opts.VerifyExisting) })
+		}
+		if (opts.Thumbnails || opts.ThumbnailsOnly) && !QuirksFor(t.Board).NoThumbnails {
+			add(PlannedFile{Name: post.thumbnailName(), Post: post, Thumbnail: true},
+				func(path string) bool { return existingFileOK(path, nil, false) })
+		}
+	}
+	return plan
+}
+
+// Download every file in the thread into dir, named as the media host names them.
+// Downloads go to a temporary file first, so a failed or mismatched download never leaves a file behind.
+// A failed download doesn't stop the others. Returns how many files were written, and a BatchError
+// listing the files that failed if any did. PlanDownloads tells what this would download.
+func (t *Thread) DownloadAllImages(ctx context.Context, dir string, opts *DownloadOptions) (int, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	client := opts.Client
+	if client == nil {
+		client = defaultClient()
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		downloaded int
+		failures   batchErrors
+	)
+	sem := make(chan struct{}, concurrency)
+	fetch := func(file PlannedFile) {
+		defer wg.Done()
+		defer func() { <-sem }()
+
+		err := saveFile(filepath.Join(dir, file.Name), opts.Sync, func(w io.Writer) error {
+			if file.Thumbnail {
+				return client.DownloadThumbnail(ctx, t.Board, file.Post, w)
+			}
+			return client.DownloadImage(ctx, t.Board, file.Post, w)
+		})
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil {
+			downloaded++
+		}
+		failures.add(file.Name, err)
+	}
+
+queueing:
+	for _, file := range t.PlanDownloads(dir, opts).Files {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break queueing
+		}
+		wg.Add(1)
+		go fetch(file)
+	}
+	wg.Wait()
+
+	if err := failures.err(); err != nil {
+		return downloaded, err
+	}
+	return downloaded, ctx.Err()
+}
+
+// Is there already a file at path, matching the post's MD5 if verify is set?
+func existingFileOK(path string, post *Post, verify bool) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	if !verify || post.FileMD5 == "" {
+		return true
+	}
+	return post.VerifyMD5(f) == nil
+}
+
+// Write path with the output of get, leaving nothing behind if get fails.
+// The output goes to a temporary file that is renamed over path once it's complete.
+// With sync the file and the rename are flushed to disk before returning.
+func saveFile(path string, sync bool, get func(io.Writer) error) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".part")
+	if err != nil {
+		return err
+	}
+
+	err = get(tmp)
+	if err == nil && sync {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if sync {
+		// Not every platform can sync a directory, the file itself is safe either way.
+		if d, err := os.Open(filepath.Dir(path)); err == nil {
+			d.Sync()
+			d.Close()
+		}
+	}
+	return nil
+}
+
+// Matches the temporary files saveFile writes to, e.g. .100.png.part123456.
+var partFileRegex = regexp.MustCompile(`^\..+\.part\d+$`)
+
+// Remove the temporary files a crash or kill during a download or Save left in dir,
+// returning how many were removed. Run it before writing to dir again, not while something is writing to it.
+func CleanPartialFiles(dir string) (int, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !partFileRegex.MatchString(entry.Name()) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,246 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func md5Base64(data string) string {
+	sum := md5.Sum([]byte(data))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestPostURLs(t *testing.T) {
+	post := &Post{Meta: Meta{RenamedFileName: 1456789012345, FileExt: ".webm"}}
+	if url := post.ImageURL("wsg"); url != "https://i.4cdn.org/wsg/1456789012345.webm" {
+		t.Fatal(url)
+	}
+	if url := post.ThumbnailURL("wsg"); url != "https://i.4cdn.org/wsg/1456789012345s.jpg" {
+		t.Fatal(url)
+	}
+
+	if name := post.NewFileName(); name != "1456789012345.webm" {
+		t.Fatal(name)
+	}
+	post.OrigFileName = "loop"
+	if name := post.FileName(); name != "loop.webm" {
+		t.Fatal(name)
+	}
+	if (&Post{}).FileName() != "" || (&Post{}).NewFileName() != "" {
+		t.Fatal("names for a post without a file")
+	}
+}
+
+func TestDownloadAllImages(t *testing.T) {
+	files := map[string]string{
+		"/g/100.png":  "first image",
+		"/g/100s.jpg": "first thumb",
+		"/g/101.jpg":  "second image",
+		"/g/101s.jpg": "second thumb",
+		"/g/102.gif":  "corrupted",
+	}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(data))
+	}))
+	defer server.Close()
+
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".png", FileMD5: md5Base64("first image")}},
+		{Meta: Meta{PostNumber: 2}},
+		{Meta: Meta{PostNumber: 3, HasFile: true, RenamedFileName: 101, FileExt: ".jpg", FileMD5: md5Base64("second image")}},
+		{Meta: Meta{PostNumber: 4, HasFile: true, RenamedFileName: 102, FileExt: ".gif", FileMD5: md5Base64("gif")}},
+		{Meta: Meta{PostNumber: 5, HasFile: true, FileDeleted: true, RenamedFileName: 103, FileExt: ".jpg"}},
+	}}
+
+	dir, err := ioutil.TempDir("", "fourchan-download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client := NewClient(WithMediaURL(server.URL), WithRateLimit(0))
+	opts := &DownloadOptions{Client: client, Concurrency: 2, SkipExisting: true, Filter: &MediaFilter{Extensions: []string{".png", ".gif"}}}
+	n, err := thread.DownloadAllImages(context.Background(), dir, opts)
+	batch, ok := err.(BatchError)
+	if !ok || n != 1 || batch.Total != 2 || len(batch.Failures) != 1 || batch.Failures[0].Item != "102.gif" {
+		t.Fatalf("expected one download and a failure, got %d %v", n, err)
+	}
+	if _, ok = batch.Failures[0].Err.(ChecksumMismatchError); !ok {
+		t.Fatalf("expected a checksum mismatch, got %v", batch.Failures[0].Err)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "100.png")); string(data) != "first image" {
+		t.Fatalf("bad file %q", data)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "102.gif")); !os.IsNotExist(err) {
+		t.Fatal("mismatched file left behind")
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+
+	// Existing files are skipped, unless they don't verify.
+	ioutil.WriteFile(filepath.Join(dir, "101.jpg"), []byte("truncated"), 0644)
+	requests = 0
+	opts = &DownloadOptions{Client: client, SkipExisting: true, VerifyExisting: true, Thumbnails: true,
+		Filter: &MediaFilter{Extensions: []string{".png", ".jpg"}}}
+	n, err = thread.DownloadAllImages(context.Background(), dir, opts)
+	if err != nil || n != 3 || requests != 3 {
+		t.Fatalf("expected 3 downloads, got %d in %d requests: %v", n, requests, err)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "101.jpg")); string(data) != "second image" {
+		t.Fatalf("bad file %q", data)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "101s.jpg")); string(data) != "second thumb" {
+		t.Fatalf("bad thumbnail %q", data)
+	}
+}
+
+func TestDownloadImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("image"))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMediaURL(server.URL), WithRateLimit(0))
+	post := &Post{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".png", FileMD5: md5Base64("image")}}
+	var buf bytes.Buffer
+	if err := client.DownloadImage(context.Background(), "g", post, &buf); err != nil || buf.String() != "image" {
+		t.Fatalf("bad download %q %v", buf.String(), err)
+	}
+
+	if err := client.DownloadImage(context.Background(), "g", &Post{}, &buf); err == nil {
+		t.Fatal("downloaded a post without a file")
+	}
+}
+
+func TestPlanDownloads(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".png", FileSize: 1000}},
+		{Meta: Meta{PostNumber: 2}},
+		{Meta: Meta{PostNumber: 3, HasFile: true, RenamedFileName: 101, FileExt: ".jpg", FileSize: 500}},
+		{Meta: Meta{PostNumber: 4, HasFile: true, RenamedFileName: 102, FileExt: ".webm", FileSize: 9000}},
+	}}
+
+	dir, err := ioutil.TempDir("", "fourchan-plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "101.jpg"), []byte("already here"), 0644)
+
+	plan := thread.PlanDownloads(dir, &DownloadOptions{SkipExisting: true, Thumbnails: true, Filter: &MediaFilter{ImagesOnly: true}})
+	var names []string
+	for _, file := range plan.Files {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, " ") != "100.png 100s.jpg 101s.jpg" || plan.Skipped != 1 || plan.Bytes != 1000 {
+		t.Fatalf("bad plan %v %+v", names, plan)
+	}
+	if !plan.Files[1].Thumbnail || plan.Files[0].Post.PostNumber != 1 {
+		t.Fatalf("bad files %+v", plan.Files)
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("planning wrote files: %v", entries)
+	}
+
+	plan = thread.PlanDownloads(dir, &DownloadOptions{SkipExisting: true, ThumbnailsOnly: true})
+	names = nil
+	for _, file := range plan.Files {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, " ") != "100s.jpg 101s.jpg 102s.jpg" || plan.Skipped != 0 || plan.Bytes != 0 {
+		t.Fatalf("bad thumbnail plan %v %+v", names, plan)
+	}
+}
+
+func TestCleanPartialFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fourchan-clean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"100.png", ".100.png.part123", ".thread.json.part98765", "notes.part1", ".hidden"} {
+		ioutil.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+	err = saveFile(filepath.Join(dir, "101.jpg"), true, func(w io.Writer) error {
+		_, err := w.Write([]byte("image"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := CleanPartialFiles(dir); err != nil || n != 2 {
+		t.Fatalf("removed %d: %v", n, err)
+	}
+	var names []string
+	entries, _ := ioutil.ReadDir(dir)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != ".hidden 100.png 101.jpg notes.part1" {
+		t.Fatalf("bad files left %v", names)
+	}
+}
+
+func TestFileExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "HEAD" {
+			t.Errorf("unexpected %s", r.Method)
+		}
+		switch r.URL.Path {
+		case "/g/100.webm":
+			w.Header().Set("Content-Length", "1000000")
+		case "/g/101.webm":
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(WithMediaURL(server.URL), WithRateLimit(0))
+	if ok, err := client.FileExists(context.Background(), "g", 100, ".webm"); !ok || err != nil {
+		t.Fatalf("expected the file, got %v %v", ok, err)
+	}
+	if ok, err := client.FileExists(context.Background(), "g", 101, ".webm"); ok || err != nil {
+		t.Fatalf("expected no file, got %v %v", ok, err)
+	}
+	if _, err := client.FileExists(context.Background(), "g", 102, ".webm"); err == nil {
+		t.Fatal("expected an error for an unexpected status")
+	}
+}
+
+func TestPlanDownloadsOriginalNames(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".png", OrigFileName: "what?"}},
+		{Meta: Meta{PostNumber: 2, HasFile: true, RenamedFileName: 101, FileExt: ".jpg"}},
+	}}
+	plan := thread.PlanDownloads("", &DownloadOptions{OriginalNames: true, Thumbnails: true})
+	var names []string
+	for _, file := range plan.Files {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, "|") != "100 what_.png|100s.jpg|101.jpg|101s.jpg" {
+		t.Fatalf("bad names %q", names)
+	}
+}
@@ -0,0 +1,113 @@
+package fourchan
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// Extensions boards accept uploads with.
+var uploadExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webm": true, ".pdf": true, ".mp4": true,
+}
+
+// A post that hasn't been submitted yet.
+type Draft struct {
+	// The thread to reply to, 0 to start a new thread.
+	ReplyTo uint64
+	Name    string
+	Subject string
+	Comment string
+
+	// Name of the file to attach, empty for none.
+	FileName string
+	// Size of the file in bytes.
+	FileSize int
+	// Dimensions of the file when it is an image.
+	FileWidth, FileHeight int
+}
+
+// The rule a draft breaks.
+type DraftRule int
+
+const (
+	// The comment is longer than the board allows.
+	RuleCommentLength DraftRule = iota
+	// The file is larger than the board allows.
+	RuleFileSize
+	// The board doesn't accept this kind of file.
+	RuleFileType
+	// The image is smaller than the board allows.
+	RuleImageSize
+	// New threads on this board need an image.
+	RuleImageRequired
+	// New threads on this board need a subject.
+	RuleSubjectRequired
+	// The board is text only.
+	RuleTextOnly
+	// Replies need a comment or a file.
+	RuleEmpty
+)
+
+// Custom error to indicate a draft breaks one of the board's rules.
+type DraftError struct {
+	Rule DraftRule
+	// Human readable explanation.
+	Msg string
+}
+
+// Explain which rule was broken.
+func (e DraftError) Error() string {
+	return e.Msg
+}
+
+// Check a draft against a board's rules before spending a captcha on it.
+// Returns every rule broken, nil if the draft looks fine.
+func (d *Draft) Validate(board *Board) []DraftError {
+	var errs []DraftError
+	fail := func(rule DraftRule, format string, args ...interface{}) {
+		errs = append(errs, DraftError{rule, fmt.Sprintf(format, args...)})
+	}
+
+	if board.MaxCommentChars > 0 {
+		if n := utf8.RuneCountInString(d.Comment); n > board.MaxCommentChars {
+			fail(RuleCommentLength, "Comment is %d characters, /%s/ allows %d", n, board.Name, board.MaxCommentChars)
+		}
+	}
+
+	if d.FileName == "" {
+		if d.ReplyTo == 0 && !board.TextOnly {
+			fail(RuleImageRequired, "New threads on /%s/ need an image", board.Name)
+		}
+		if d.ReplyTo != 0 && strings.TrimSpace(d.Comment) == "" {
+			fail(RuleEmpty, "Replies need a comment or a file")
+		}
+	} else {
+		ext := strings.ToLower(path.Ext(d.FileName))
+		if board.TextOnly {
+			fail(RuleTextOnly, "/%s/ doesn't take files", board.Name)
+		} else if !uploadExtensions[ext] {
+			fail(RuleFileType, "Files of type %q aren't allowed", ext)
+		}
+
+		maxSize := board.MaxFileSize
+		if ext == ".webm" && board.MaxWebmFileSize > 0 {
+			maxSize = board.MaxWebmFileSize
+		}
+		if maxSize > 0 && d.FileSize > maxSize {
+			fail(RuleFileSize, "File is %d bytes, /%s/ allows %d", d.FileSize, board.Name, maxSize)
+		}
+
+		if imageExtensions[ext] && (d.FileWidth < board.MinImageWidth || d.FileHeight < board.MinImageHeight) {
+			fail(RuleImageSize, "Image is %dx%d, /%s/ needs at least %dx%d",
+				d.FileWidth, d.FileHeight, board.Name, board.MinImageWidth, board.MinImageHeight)
+		}
+	}
+
+	if d.ReplyTo == 0 && board.RequireSubject && strings.TrimSpace(d.Subject) == "" {
+		fail(RuleSubjectRequired, "New threads on /%s/ need a subject", board.Name)
+	}
+
+	return errs
+}
@@ -0,0 +1,52 @@
+package fourchan
+
+import (
+	"strings"
+	"testing"
+)
+
+func rules(errs []DraftError) []DraftRule {
+	var r []DraftRule
+	for _, err := range errs {
+		r = append(r, err.Rule)
+	}
+	return r
+}
+
+func TestDraftValidate(t *testing.T) {
+	g := &Board{Name: "g", MaxCommentChars: 10, MaxFileSize: 1000, MaxWebmFileSize: 500, MinImageWidth: 10, MinImageHeight: 10}
+	pol := &Board{Name: "pol", RequireSubject: true}
+	text := &Board{Name: "news", TextOnly: true}
+
+	tests := []struct {
+		draft Draft
+		board *Board
+		rules []DraftRule
+	}{
+		{Draft{ReplyTo: 1, Comment: "fine"}, g, nil},
+		{Draft{ReplyTo: 1, Comment: strings.Repeat("a", 11)}, g, []DraftRule{RuleCommentLength}},
+		{Draft{ReplyTo: 1, Comment: "ééééé"}, g, nil},
+		{Draft{ReplyTo: 1}, g, []DraftRule{RuleEmpty}},
+		{Draft{Comment: "op"}, g, []DraftRule{RuleImageRequired}},
+		{Draft{FileName: "a.png", FileSize: 100, FileWidth: 10, FileHeight: 10}, g, nil},
+		{Draft{FileName: "a.webm", FileSize: 600}, g, []DraftRule{RuleFileSize}},
+		{Draft{FileName: "a.exe", FileSize: 10}, g, []DraftRule{RuleFileType}},
+		{Draft{FileName: "a.JPG", FileSize: 10, FileWidth: 5, FileHeight: 50}, g, []DraftRule{RuleImageSize}},
+		{Draft{FileName: "a.jpg"}, pol, []DraftRule{RuleSubjectRequired}},
+		{Draft{Comment: "news", Subject: "s"}, text, nil},
+		{Draft{ReplyTo: 1, FileName: "a.jpg"}, text, []DraftRule{RuleTextOnly}},
+	}
+
+	for i, test := range tests {
+		errs := test.draft.Validate(test.board)
+		got := rules(errs)
+		if len(got) != len(test.rules) {
+			t.Fatalf("%d: %v != %v (%v)", i, got, test.rules, errs)
+		}
+		for j := range got {
+			if got[j] != test.rules[j] {
+				t.Fatalf("%d: %v != %v (%v)", i, got, test.rules, errs)
+			}
+		}
+	}
+}
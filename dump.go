@@ -0,0 +1,78 @@
+package fourchan
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Custom error for a response that couldn't be decoded.
+type DecodeError struct {
+	URL string
+	// Where the response was written, see WithResponseDumps. Empty if it wasn't.
+	Dump string
+	Err  error
+}
+
+// Say what couldn't be decoded, and where to find it.
+func (e DecodeError) Error() string {
+	if e.Dump != "" {
+		return fmt.Sprintf("Decoding %s: %v (response saved to %s)", e.URL, e.Err, e.Dump)
+	}
+	return fmt.Sprintf("Decoding %s: %v", e.URL, e.Err)
+}
+
+// The JSON error, for errors.As.
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Write each API response that fails to decode to a file in dir, status line, headers and body,
+// for attaching to a bug report. The file is named in the DecodeError.
+// Cookie and authorization headers are kept but their values are written as "[redacted]".
+func WithResponseDumps(dir string) Option {
+	return func(c *Client) {
+		c.dumpDir = dir
+	}
+}
+
+// Headers whose values are never dumped.
+var redactedHeaders = []string{"Set-Cookie", "Cookie", "Authorization"}
+
+// Anything that can't be in a dump's file name.
+var dumpNameRegex = regexp.MustCompile(`[^A-Za-z0-9.]+`)
+
+// Build the error for a response that failed to decode, dumping it if the client is set to.
+func (c *Client) decodeError(url string, resp *http.Response, body []byte, err error) error {
+	if c.dumpDir == "" {
+		return DecodeError{URL: url, Err: err}
+	}
+
+	header := resp.Header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, "[redacted]")
+		}
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "GET %s\n%s %s\n", url, resp.Proto, resp.Status)
+	header.Write(&b)
+	b.WriteString("\n")
+	b.Write(body)
+
+	f, dumpErr := ioutil.TempFile(c.dumpDir, strings.Trim(dumpNameRegex.ReplaceAllString(resp.Request.URL.Path, "_"), "_")+"-*.txt")
+	if dumpErr != nil {
+		return DecodeError{URL: url, Err: err}
+	}
+	_, dumpErr = f.Write(b.Bytes())
+	if closeErr := f.Close(); dumpErr == nil {
+		dumpErr = closeErr
+	}
+	if dumpErr != nil {
+		return DecodeError{URL: url, Err: err}
+	}
+	return DecodeError{url, f.Name(), err}
+}
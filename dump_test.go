@@ -0,0 +1,62 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseDumps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Test", "kept")
+		w.Write([]byte(`{"posts": [{"no": "one"}]}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server).LoadThread(context.Background(), "g", 1)
+	if de, ok := err.(DecodeError); !ok || de.Dump != "" || !strings.HasSuffix(de.URL, "/g/thread/1.json") {
+		t.Fatalf("expected an undumped DecodeError, got %v", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("DecodeError doesn't unwrap to the JSON error: %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "fourchan-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithResponseDumps(dir))
+	_, err = c.LoadThread(context.Background(), "g", 1)
+	de, ok := err.(DecodeError)
+	if !ok || de.Dump == "" || !strings.Contains(de.Error(), de.Dump) {
+		t.Fatalf("expected a dumped DecodeError, got %v", err)
+	}
+	data, err := ioutil.ReadFile(de.Dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dump := string(data)
+	if !strings.Contains(dump, "200 OK") || !strings.Contains(dump, "X-Test: kept") || !strings.HasSuffix(dump, `{"posts": [{"no": "one"}]}`) {
+		t.Fatalf("bad dump %q", dump)
+	}
+	if strings.Contains(dump, "secret") {
+		t.Fatalf("dump has the cookie %q", dump)
+	}
+
+	// Watched threads are polled with a conditional request of their own, which dumps the same way.
+	_, err = c.loadThreadIfModified(context.Background(), "g", "1", time.Time{})
+	if de, ok := err.(DecodeError); !ok || de.Dump == "" {
+		t.Fatalf("expected a dumped DecodeError from a watcher poll, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package fourchan
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Options for finding duplicate comments.
+type DuplicateOptions struct {
+	// Comments shorter than this after normalization are ignored, defaults to 20 runes.
+	// Short replies like "bump" or "this" would otherwise dominate the results.
+	MinLength int
+	// When above 0, also group comments whose word shingles have at least
+	// this Jaccard similarity (0-1]. Exact duplicates are always grouped.
+	Similarity float64
+}
+
+// A post along with where it was found.
+type PostLocation struct {
+	Board  string
+	Thread ThreadID
+	Post   *Post
+}
+
+// A set of posts with the same or nearly the same comment.
+type DuplicateGroup struct {
+	// The normalized text of the first post in the group.
+	Text string
+	// Each post in the group, in the order they were found.
+	Posts []PostLocation
+}
+
+// Normalize a comment for comparison.
+// Quote links are dropped, case and punctuation are folded away and whitespace is collapsed.
+func normalizeComment(comment string) string {
+	var words []string
+	for _, line := range strings.Split(commentText(comment), "\n") {
+		for _, word := range strings.Fields(line) {
+			if strings.HasPrefix(word, ">>") {
+				continue
+			}
+			word = strings.Map(func(r rune) rune {
+				if unicode.IsLetter(r) || unicode.IsNumber(r) {
+					return unicode.ToLower(r)
+				}
+				return -1
+			}, word)
+			if word != "" {
+				words = append(words, word)
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Word trigrams of normalized text.
+func shingles(text string) map[string]bool {
+	words := strings.Fields(text)
+	set := map[string]bool{}
+	if len(words) < 3 {
+		set[text] = true
+		return set
+	}
+	for i := 0; i+3 <= len(words); i++ {
+		set[strings.Join(words[i:i+3], " ")] = true
+	}
+	return set
+}
+
+// Jaccard similarity of two sets.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// Find comments posted more than once across the given threads.
+// Groups are returned largest first.
+func FindDuplicates(threads []*Thread, opts *DuplicateOptions) []DuplicateGroup {
+	if opts == nil {
+		opts = &DuplicateOptions{}
+	}
+	minLength := opts.MinLength
+	if minLength == 0 {
+		minLength = 20
+	}
+
+	var groups []*DuplicateGroup
+	byText := map[string]*DuplicateGroup{}
+	for _, thread := range threads {
+		op := firstPostNumber(thread)
+		for i := range thread.Posts {
+			post := &thread.Posts[i]
+			text := normalizeComment(post.Comment)
+			if len([]rune(text)) < minLength {
+				continue
+			}
+			group, ok := byText[text]
+			if !ok {
+				group = &DuplicateGroup{Text: text}
+				byText[text] = group
+				groups = append(groups, group)
+			}
+			group.Posts = append(group.Posts, PostLocation{thread.Board, op, post})
+		}
+	}
+
+	if opts.Similarity > 0 {
+		groups = mergeSimilar(groups, opts.Similarity)
+	}
+
+	var result []DuplicateGroup
+	for _, group := range groups {
+		if len(group.Posts) > 1 {
+			result = append(result, *group)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Posts) > len(result[j].Posts)
+	})
+
+	return result
+}
+
+// Merge groups whose texts are at least threshold similar.
+func mergeSimilar(groups []*DuplicateGroup, threshold float64) []*DuplicateGroup {
+	sets := make([]map[string]bool, len(groups))
+	for i, group := range groups {
+		sets[i] = shingles(group.Text)
+	}
+
+	// Union find over the groups.
+	parent := make([]int, len(groups))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range groups {
+		for j := i + 1; j < len(groups); j++ {
+			if jaccard(sets[i], sets[j]) >= threshold {
+				a, b := find(i), find(j)
+				if a < b {
+					parent[b] = a
+				} else if b < a {
+					parent[a] = b
+				}
+			}
+		}
+	}
+
+	var merged []*DuplicateGroup
+	for i, group := range groups {
+		root := find(i)
+		if root == i {
+			merged = append(merged, group)
+			continue
+		}
+		groups[root].Posts = append(groups[root].Posts, group.Posts...)
+	}
+	return merged
+}
+
+// Find comments posted more than once in the thread.
+func (t *Thread) Duplicates(opts *DuplicateOptions) []DuplicateGroup {
+	return FindDuplicates([]*Thread{t}, opts)
+}
@@ -0,0 +1,37 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	pasta := "What the heck did you just say about me, you little kid?"
+	a := &Thread{Board: "b", Posts: []Post{
+		{Comment: pasta, Meta: Meta{PostNumber: 1}},
+		{Comment: "<a href=\"#p1\" class=\"quotelink\">&gt;&gt;1</a><br>WHAT the heck did you just say about me,   you little kid", Meta: Meta{PostNumber: 2}},
+		{Comment: "bump", Meta: Meta{PostNumber: 3}},
+		{Comment: "bump", Meta: Meta{PostNumber: 4}},
+	}}
+	b := &Thread{Board: "b", Posts: []Post{
+		{Comment: "What the heck did you just say about me, you little kiddo? I'll have you know", Meta: Meta{PostNumber: 10}},
+		{Comment: pasta, Meta: Meta{PostNumber: 11}},
+	}}
+
+	groups := FindDuplicates([]*Thread{a, b}, nil)
+	if len(groups) != 1 || len(groups[0].Posts) != 3 {
+		t.Fatalf("bad exact groups %+v", groups)
+	}
+	if last := groups[0].Posts[2]; last.Thread != 10 || last.Post.PostNumber != 11 {
+		t.Fatalf("bad location %+v", last)
+	}
+
+	groups = FindDuplicates([]*Thread{a, b}, &DuplicateOptions{Similarity: 0.5})
+	if len(groups) != 1 || len(groups[0].Posts) != 4 {
+		t.Fatalf("bad fuzzy groups %+v", groups)
+	}
+
+	groups = a.Duplicates(&DuplicateOptions{MinLength: 1})
+	if len(groups) != 2 {
+		t.Fatalf("bad short groups %+v", groups)
+	}
+}
@@ -0,0 +1,231 @@
+package fourchan
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options for rendering threads into an EPUB.
+type EPUBOptions struct {
+	// The title of the book, defaults to the subject of the first thread.
+	Title string
+	// Language of the book, defaults to "en".
+	Language string
+	// Fetch thumbnails from the media host and include them inline.
+	// This is best effort, a thumbnail that can't be fetched is left out and the book written without it.
+	Thumbnails bool
+	// Called with each post whose thumbnail couldn't be fetched and why. Nil ignores failures.
+	OnThumbnailError func(*Post, error)
+	// The client to fetch thumbnails with, defaults to DefaultClient.
+	Client *Client
+}
+
+// The title of a thread, its subject if it has one and /board/No.id otherwise.
+func threadTitle(thread *Thread) string {
+	if len(thread.Posts) > 0 {
+		if subject := commentText(thread.Posts[0].Subject); subject != "" {
+			return subject
+		}
+		return fmt.Sprintf("/%s/ No.%d", thread.Board, thread.Posts[0].PostNumber)
+	}
+	return "/" + thread.Board + "/"
+}
+
+// Escape text for use in XHTML, keeping line breaks.
+func xhtmlText(text string) string {
+	return strings.Replace(html.EscapeString(text), "\n", "<br/>\n", -1)
+}
+
+// Render threads into an EPUB written to w, one chapter per thread.
+func WriteEPUB(w io.Writer, threads []*Thread, opts *EPUBOptions) error {
+	if opts == nil {
+		opts = &EPUBOptions{}
+	}
+	if len(threads) == 0 {
+		return fmt.Errorf("no threads to export")
+	}
+	title := opts.Title
+	if title == "" {
+		title = threadTitle(threads[0])
+	}
+	lang := opts.Language
+	if lang == "" {
+		lang = "en"
+	}
+	client := opts.Client
+	if client == nil {
+		client = defaultClient()
+	}
+
+	z := zip.NewWriter(w)
+
+	// The mimetype must come first and be stored uncompressed.
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	if err = writeZipFile(z, "META-INF/container.xml", epubContainer); err != nil {
+		return err
+	}
+
+	var manifest, spine, nav, ncx strings.Builder
+	for i, thread := range threads {
+		name := "thread" + strconv.Itoa(i+1) + ".xhtml"
+		id := "thread" + strconv.Itoa(i+1)
+		chapterTitle := html.EscapeString(threadTitle(thread))
+
+		var body strings.Builder
+		for j := range thread.Posts {
+			post := &thread.Posts[j]
+			fmt.Fprintf(&body, "<div class=\"post\" id=\"p%d\">\n", post.PostNumber)
+			fmt.Fprintf(&body, "<p class=\"header\"><b>%s</b>%s %s No.%d</p>\n",
+				html.EscapeString(post.Name), html.EscapeString(post.TripCode),
+				html.EscapeString(post.Time), post.PostNumber)
+
+			if opts.Thumbnails && post.HasFile && !post.FileDeleted {
+				image := "images/" + strconv.FormatUint(post.RenamedFileName, 10) + "s.jpg"
+				data, err := client.fetchThumbnail(context.Background(), thread.Board, post)
+				if err != nil && opts.OnThumbnailError != nil {
+					opts.OnThumbnailError(post, err)
+				}
+				if err == nil {
+					if err = writeZipFile(z, "OEBPS/"+image, string(data)); err != nil {
+						return err
+					}
+					imageID := "img" + strconv.FormatUint(post.RenamedFileName, 10)
+					fmt.Fprintf(&manifest, "<item id=\"%s\" href=\"%s\" media-type=\"image/jpeg\"/>\n", imageID, image)
+					fmt.Fprintf(&body, "<p><img src=\"%s\" alt=\"%s\"/></p>\n", image, html.EscapeString(post.FileName()))
+				}
+			}
+
+			if post.Subject != "" {
+				fmt.Fprintf(&body, "<p class=\"subject\"><b>%s</b></p>\n", xhtmlText(commentText(post.Subject)))
+			}
+			if post.Comment != "" {
+				fmt.Fprintf(&body, "<p>%s</p>\n", xhtmlText(commentText(post.Comment)))
+			}
+			body.WriteString("</div>\n")
+		}
+
+		chapter := fmt.Sprintf(epubChapter, lang, chapterTitle, chapterTitle, body.String())
+		if err = writeZipFile(z, "OEBPS/"+name, chapter); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&manifest, "<item id=\"%s\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n", id, name)
+		fmt.Fprintf(&spine, "<itemref idref=\"%s\"/>\n", id)
+		fmt.Fprintf(&nav, "<li><a href=\"%s\">%s</a></li>\n", name, chapterTitle)
+		fmt.Fprintf(&ncx, "<navPoint id=\"%s\" playOrder=\"%d\"><navLabel><text>%s</text></navLabel><content src=\"%s\"/></navPoint>\n",
+			id, i+1, chapterTitle, name)
+	}
+
+	identifier := fmt.Sprintf("urn:4chan:%s:%d", threads[0].Board, firstPostNumber(threads[0]))
+	escapedTitle := html.EscapeString(title)
+	modified := time.Now().UTC().Format("2006-01-02T15:04:05Z")
+
+	opf := fmt.Sprintf(epubPackage, identifier, escapedTitle, lang, modified, manifest.String(), spine.String())
+	if err = writeZipFile(z, "OEBPS/content.opf", opf); err != nil {
+		return err
+	}
+	if err = writeZipFile(z, "OEBPS/nav.xhtml", fmt.Sprintf(epubNav, lang, escapedTitle, nav.String())); err != nil {
+		return err
+	}
+	if err = writeZipFile(z, "OEBPS/toc.ncx", fmt.Sprintf(epubNCX, identifier, escapedTitle, ncx.String())); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+// The post number of the OP, 0 for an empty thread.
+func firstPostNumber(thread *Thread) uint64 {
+	if len(thread.Posts) == 0 {
+		return 0
+	}
+	return thread.Posts[0].PostNumber
+}
+
+// Download the thumbnail for a post.
+func (c *Client) fetchThumbnail(ctx context.Context, board string, post *Post) ([]byte, error) {
+	var b bytes.Buffer
+	if err := c.DownloadThumbnail(ctx, board, post, &b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Add a deflated file to the archive.
+func writeZipFile(z *zip.Writer, name, contents string) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, contents)
+	return err
+}
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+const epubPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="bookid">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="bookid">%s</dc:identifier>
+<dc:title>%s</dc:title>
+<dc:language>%s</dc:language>
+<meta property="dcterms:modified">%s</meta>
+</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+%s</manifest>
+<spine toc="ncx">
+%s</spine>
+</package>
+`
+
+const epubNav = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s">
+<head><title>%s</title></head>
+<body>
+<nav epub:type="toc">
+<ol>
+%s</ol>
+</nav>
+</body>
+</html>
+`
+
+const epubNCX = `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+<head><meta name="dtb:uid" content="%s"/></head>
+<docTitle><text>%s</text></docTitle>
+<navMap>
+%s</navMap>
+</ncx>
+`
+
+const epubChapter = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s">
+<head><title>%s</title></head>
+<body>
+<h1>%s</h1>
+%s</body>
+</html>
+`
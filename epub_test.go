@@ -0,0 +1,75 @@
+package fourchan
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteEPUB(t *testing.T) {
+	thread := &Thread{
+		Board: "lit",
+		Posts: []Post{
+			{Subject: "Story &amp; time", Comment: "Once upon<br>a time", Meta: Meta{PostNumber: 1}},
+			{Comment: "&gt;&gt;1<br>bump", Meta: Meta{PostNumber: 2, ReplyTo: 1}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEPUB(&buf, []*Thread{thread}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.File[0].Name != "mimetype" || r.File[0].Method != zip.Store {
+		t.Fatal("mimetype is not the first stored entry")
+	}
+
+	files := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(data)
+	}
+
+	chapter, ok := files["OEBPS/thread1.xhtml"]
+	if !ok {
+		t.Fatal("missing chapter")
+	}
+	if !strings.Contains(chapter, "<h1>Story &amp; time</h1>") {
+		t.Fatalf("bad chapter title: %s", chapter)
+	}
+	if !strings.Contains(chapter, "Once upon<br/>\na time") {
+		t.Fatalf("bad chapter body: %s", chapter)
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], "<dc:title>Story &amp; time</dc:title>") {
+		t.Fatal("bad package title")
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	thread.Posts[1].HasFile, thread.Posts[1].RenamedFileName, thread.Posts[1].FileExt = true, 100, ".jpg"
+	var failed []uint64
+	opts := &EPUBOptions{Thumbnails: true, Client: NewClient(WithMediaURL(server.URL), WithRateLimit(0)),
+		OnThumbnailError: func(p *Post, err error) { failed = append(failed, p.PostNumber) }}
+	if err := WriteEPUB(ioutil.Discard, []*Thread{thread}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0] != 2 {
+		t.Fatalf("thumbnail failures not reported: %v", failed)
+	}
+}
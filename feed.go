@@ -0,0 +1,269 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Options for building a media feed.
+type FeedOptions struct {
+	// The title of the feed, defaults to the board, e.g. /wg/ media.
+	Title string
+	// Most items in the feed, the newest are kept, defaults to 100.
+	Limit int
+	// Only list files passing this filter, nil lists every file.
+	Filter *MediaFilter
+	// Link to files on this client's media host, see WithMediaURL. Defaults to DefaultClient,
+	// or the client building the feed for Client.BoardMediaFeed.
+	Client *Client
+}
+
+// A file in a media feed.
+type FeedItem struct {
+	GalleryItem
+	// When the file was posted.
+	Time time.Time `json:"time"`
+	// The file's MIME type, from its extension.
+	ContentType string `json:"content_type"`
+}
+
+// The newest files posted across one or more threads, for feed readers.
+type MediaFeed struct {
+	Title string
+	// Where the board is on the site.
+	Link string
+	// When the newest file was posted.
+	Updated time.Time
+	// Newest first.
+	Items []FeedItem
+}
+
+// MIME types of the files 4chan allows.
+var mediaContentTypes = map[string]string{
+	".jpg": "image/jpeg", ".jpeg": "image/jpeg", ".png": "image/png", ".gif": "image/gif",
+	".webm": "video/webm", ".mp4": "video/mp4", ".pdf": "application/pdf", ".swf": "application/x-shockwave-flash",
+}
+
+// Collect the newest files in threads into a feed linking straight to the media host.
+// The feed is named after the board of the first thread.
+func BuildMediaFeed(threads []*Thread, opts *FeedOptions) *MediaFeed {
+	if opts == nil {
+		opts = &FeedOptions{}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	client := opts.Client
+	if client == nil {
+		client = defaultClient()
+	}
+
+	feed := &MediaFeed{Title: opts.Title, Items: []FeedItem{}}
+	seen := map[string]bool{}
+	for _, thread := range threads {
+		if feed.Link == "" {
+			feed.Link = fmt.Sprintf("%s/%s/", boardsURL, thread.Board)
+			if feed.Title == "" {
+				feed.Title = "/" + thread.Board + "/ media"
+			}
+		}
+		op := firstPostNumber(thread)
+		for _, post := range thread.Filter(opts.Filter.Match) {
+			item := galleryItem(client, thread.Board, op, post, &GalleryOptions{})
+			// Threads given more than once, e.g. over several polls, would list a file twice.
+			if seen[item.URL] {
+				continue
+			}
+			seen[item.URL] = true
+			contentType := mediaContentTypes[strings.ToLower(post.FileExt)]
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			feed.Items = append(feed.Items, FeedItem{item, post.PostTime(), contentType})
+		}
+	}
+
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		if !feed.Items[i].Time.Equal(feed.Items[j].Time) {
+			return feed.Items[i].Time.After(feed.Items[j].Time)
+		}
+		return feed.Items[i].Post > feed.Items[j].Post
+	})
+	if len(feed.Items) > limit {
+		feed.Items = feed.Items[:limit]
+	}
+	if len(feed.Items) > 0 {
+		feed.Updated = feed.Items[0].Time
+	}
+	return feed
+}
+
+// Build a feed of the newest files on a board, see Client.BoardMediaFeed.
+func BoardMediaFeed(board string, opts *FeedOptions) (*MediaFeed, error) {
+	return defaultClient().BoardMediaFeed(context.Background(), board, opts)
+}
+
+// Build a feed of the newest files on a board from its catalog, the OPs and latest replies of every thread.
+// Polling this as often as a feed reader would lists new files as they are posted.
+func (c *Client) BoardMediaFeed(ctx context.Context, board string, opts *FeedOptions) (*MediaFeed, error) {
+	catalog, err := c.LoadCatalog(ctx, board)
+	if err != nil {
+		return nil, err
+	}
+	withClient := FeedOptions{Client: c}
+	if opts != nil {
+		withClient = *opts
+		if withClient.Client == nil {
+			withClient.Client = c
+		}
+	}
+	feed := BuildMediaFeed(catalog.Threads(), &withClient)
+	if feed.Link == "" {
+		feed.Link = fmt.Sprintf("%s/%s/", boardsURL, board)
+	}
+	if feed.Title == "" {
+		feed.Title = "/" + board + "/ media"
+	}
+	return feed, nil
+}
+
+// A feed item's title, the file's original name.
+func (item *FeedItem) title() string {
+	if item.FileName != "" {
+		return item.FileName
+	}
+	return fmt.Sprintf("No.%d", item.Post)
+}
+
+// A feed item's thumbnail as HTML, for readers that show a description.
+// Files without a thumbnail, e.g. on /f/, are linked by name instead.
+func (item *FeedItem) summary() string {
+	if item.ThumbnailURL == "" {
+		return fmt.Sprintf(`<a href="%s">%s</a> %dx%d`, html.EscapeString(item.URL), html.EscapeString(item.title()),
+			item.Width, item.Height)
+	}
+	return fmt.Sprintf(`<a href="%s"><img src="%s" width="%d" height="%d"></a> %dx%d`, html.EscapeString(item.URL),
+		html.EscapeString(item.ThumbnailURL), item.ThumbnailWidth, item.ThumbnailHeight, item.Width, item.Height)
+}
+
+// RSS 2.0, as much of it as a media feed needs.
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	GUID        string       `xml:"guid"`
+	PubDate     string       `xml:"pubDate"`
+	Description string       `xml:"description"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// Write the feed as RSS 2.0, each file an enclosure of its item.
+func (f *MediaFeed) WriteRSS(w io.Writer) error {
+	doc := rss{Version: "2.0", Channel: rssChannel{Title: f.Title, Link: f.Link, Description: f.Title}}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for i := range f.Items {
+		item := &f.Items[i]
+		size := item.FileSize
+		if size < 0 {
+			size = 0
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.title(),
+			Link:        item.PostURL,
+			GUID:        item.URL,
+			PubDate:     item.Time.UTC().Format(time.RFC1123Z),
+			Description: item.summary(),
+			Enclosure:   rssEnclosure{item.URL, size, item.ContentType},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// JSON Feed 1.1, as much of it as a media feed needs.
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	Image         string               `json:"image"`
+	DatePublished string               `json:"date_published"`
+	Attachments   []jsonFeedAttachment `json:"attachments"`
+}
+
+type jsonFeedAttachment struct {
+	URL         string `json:"url"`
+	MIMEType    string `json:"mime_type"`
+	SizeInBytes int    `json:"size_in_bytes,omitempty"`
+}
+
+// Write the feed as a JSON Feed, each file an attachment of its item.
+func (f *MediaFeed) WriteJSONFeed(w io.Writer) error {
+	doc := jsonFeed{Version: "https://jsonfeed.org/version/1.1", Title: f.Title, HomePageURL: f.Link, Items: []jsonFeedItem{}}
+	for i := range f.Items {
+		item := &f.Items[i]
+		size := item.FileSize
+		if size < 0 {
+			size = 0
+		}
+		doc.Items = append(doc.Items, jsonFeedItem{
+			ID:            item.URL,
+			URL:           item.PostURL,
+			Title:         item.title(),
+			ContentHTML:   item.summary(),
+			Image:         item.ThumbnailURL,
+			DatePublished: item.Time.UTC().Format(time.RFC3339),
+			Attachments:   []jsonFeedAttachment{{item.URL, item.ContentType, size}},
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&doc)
+}
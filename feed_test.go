@@ -0,0 +1,97 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildMediaFeed(t *testing.T) {
+	walls := &Thread{Board: "wg", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, UnixTime: 100, HasFile: true, RenamedFileName: 100, FileExt: ".jpg", FileSize: 2000}},
+		{Meta: Meta{PostNumber: 2, UnixTime: 300, HasFile: true, RenamedFileName: 102, FileExt: ".webm", FileSize: 9000}},
+		{Meta: Meta{PostNumber: 3, UnixTime: 400}},
+	}}
+	walls.Posts[1].OrigFileName = "loop"
+	more := &Thread{Board: "wg", Posts: []Post{
+		{Meta: Meta{PostNumber: 10, UnixTime: 200, HasFile: true, RenamedFileName: 101, FileExt: ".png"}},
+		{Meta: Meta{PostNumber: 11, UnixTime: 500, HasFile: true, FileDeleted: true, RenamedFileName: 103, FileExt: ".png"}},
+	}}
+
+	feed := BuildMediaFeed([]*Thread{walls, more, walls}, nil)
+	if feed.Title != "/wg/ media" || feed.Link != "https://boards.4chan.org/wg/" || len(feed.Items) != 3 || feed.Updated.Unix() != 300 {
+		t.Fatalf("bad feed %+v", feed)
+	}
+	var posts []uint64
+	for _, item := range feed.Items {
+		posts = append(posts, item.Post)
+	}
+	if fmt.Sprint(posts) != "[2 10 1]" || feed.Items[0].ContentType != "video/webm" || feed.Items[1].Thread != 10 {
+		t.Fatalf("bad items %v %+v", posts, feed.Items)
+	}
+
+	if feed = BuildMediaFeed([]*Thread{walls, more}, &FeedOptions{Limit: 1, Filter: &MediaFilter{ImagesOnly: true}}); len(feed.Items) != 1 || feed.Items[0].Post != 10 {
+		t.Fatalf("bad filtered feed %+v", feed.Items)
+	}
+
+	feed = BuildMediaFeed([]*Thread{walls}, &FeedOptions{Title: "Loops", Filter: &MediaFilter{VideosOnly: true}})
+	var buf bytes.Buffer
+	if err := feed.WriteRSS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc rss
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("bad RSS %v\n%s", err, buf.String())
+	}
+	item := doc.Channel.Items[0]
+	if doc.Channel.Title != "Loops" || len(doc.Channel.Items) != 1 || item.Title != "loop.webm" || item.Link != "https://boards.4chan.org/wg/thread/1#p2" ||
+		item.Enclosure != (rssEnclosure{"https://i.4cdn.org/wg/102.webm", 9000, "video/webm"}) {
+		t.Fatalf("bad RSS %+v", doc)
+	}
+
+	buf.Reset()
+	if err := feed.WriteJSONFeed(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var jf jsonFeed
+	if err := json.Unmarshal(buf.Bytes(), &jf); err != nil {
+		t.Fatal(err)
+	}
+	if jf.Version != "https://jsonfeed.org/version/1.1" || len(jf.Items) != 1 || jf.Items[0].Attachments[0].MIMEType != "video/webm" ||
+		jf.Items[0].Image != "https://i.4cdn.org/wg/102s.jpg" || !strings.Contains(jf.Items[0].ContentHTML, "<img") {
+		t.Fatalf("bad JSON Feed %s", buf.String())
+	}
+
+	flash := &Thread{Board: "f", Posts: []Post{{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".swf", OrigFileName: "game"}}}}
+	if summary := BuildMediaFeed([]*Thread{flash}, nil).Items[0].summary(); strings.Contains(summary, "<img") || !strings.Contains(summary, ">game.swf</a>") {
+		t.Fatalf("bad summary without a thumbnail %s", summary)
+	}
+}
+
+func TestBoardMediaFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"page": 1, "threads": [
+			{"no": 1, "time": 100, "tim": 100, "ext": ".jpg", "fsize": 10, "omitted_posts": 5,
+			 "last_replies": [{"no": 9, "resto": 1, "time": 200, "tim": 101, "ext": ".gif"}]}
+		]}]`)
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIURL(server.URL), WithMediaURL("https://media.example"), WithRateLimit(0))
+	feed, err := client.BoardMediaFeed(context.Background(), "wg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if feed.Title != "/wg/ media" || len(feed.Items) != 2 || feed.Items[0].Post != 9 || feed.Items[0].ContentType != "image/gif" {
+		t.Fatalf("bad feed %+v", feed)
+	}
+	if feed.Items[0].URL != "https://media.example/wg/101.gif" || feed.Items[0].ThumbnailURL != "https://media.example/wg/101s.jpg" {
+		t.Fatalf("not linked to the client's media host: %s %s", feed.Items[0].URL, feed.Items[0].ThumbnailURL)
+	}
+}
@@ -0,0 +1,77 @@
+package fourchan
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Options for making file names safe to write on any OS.
+type SanitizeOptions struct {
+	// Replaces each character some file system doesn't allow, defaults to "_".
+	Replacement string
+	// Longest name in bytes, defaults to 255, which most file systems allow.
+	// Longer names are cut before the extension, which is kept.
+	MaxLength int
+}
+
+// Names Windows reserves for devices, with or without an extension.
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// Make a file name, such as a post's original file name, safe to write on Windows, macOS and Linux.
+// Path separators, characters Windows doesn't allow and control characters are replaced, as are the trailing
+// dots and spaces Windows drops. Reserved names like CON or nul.txt get the replacement added, and
+// names that are too long are shortened without splitting a character.
+func SanitizeFileName(name string, opts *SanitizeOptions) string {
+	if opts == nil {
+		opts = &SanitizeOptions{}
+	}
+	replacement := opts.Replacement
+	if replacement == "" {
+		replacement = "_"
+	}
+	maxLength := opts.MaxLength
+	if maxLength <= 0 {
+		maxLength = 255
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || r == utf8.RuneError || strings.ContainsRune(`<>:"/\|?*`, r) {
+			b.WriteString(replacement)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	name = b.String()
+
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		name = trimmed + replacement
+	}
+	if name == "" {
+		name = replacement
+	}
+	base := name
+	if dot := strings.IndexByte(base, '.'); dot >= 0 {
+		base = base[:dot]
+	}
+	if reservedFileNames[strings.ToUpper(strings.TrimRight(base, " "))] {
+		name = base + replacement + name[len(base):]
+	}
+
+	if len(name) > maxLength {
+		ext := ""
+		if dot := strings.LastIndexByte(name, '.'); dot > 0 && len(name)-dot <= maxLength/2 {
+			ext = name[dot:]
+		}
+		cut := maxLength - len(ext)
+		for cut > 0 && !utf8.RuneStart(name[cut]) {
+			cut--
+		}
+		name = name[:cut] + ext
+	}
+	return name
+}
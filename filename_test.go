@@ -0,0 +1,36 @@
+package fourchan
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFileName(t *testing.T) {
+	for _, test := range []struct {
+		name, want string
+	}{
+		{"desk.jpg", "desk.jpg"},
+		{`what?: a "test"/run.png`, "what__ a _test__run.png"},
+		{"tab\there.gif", "tab_here.gif"},
+		{"trailing. ", "trailing_"},
+		{"CON", "CON_"},
+		{"nul.txt", "nul_.txt"},
+		{"com1 .tar.gz", "com1 _.tar.gz"},
+		{"console.txt", "console.txt"},
+		{"", "_"},
+		{"日本語.webm", "日本語.webm"},
+	} {
+		if got := SanitizeFileName(test.name, nil); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	if got := SanitizeFileName("a<b", &SanitizeOptions{Replacement: "-"}); got != "a-b" {
+		t.Errorf("bad replacement %q", got)
+	}
+	long := SanitizeFileName(strings.Repeat("日", 100)+".webm", &SanitizeOptions{MaxLength: 20})
+	if len(long) > 20 || !strings.HasSuffix(long, ".webm") || !utf8.ValidString(long) {
+		t.Errorf("bad shortened name %q", long)
+	}
+}
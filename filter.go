@@ -0,0 +1,408 @@
+package fourchan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A compiled filter expression selecting posts.
+//
+// Expressions compare post fields against literals and combine the results:
+//
+//	subject ~ "ppg|python" && replies > 50 && !sticky
+//
+// Strings support ==, !=, the regular expression matches ~ and !~, and contains, which ignores
+// case and accents like ContainsFold.
+// Numbers support ==, !=, <, <=, > and >=. Boolean fields can be used on their own.
+// Terms combine with &&, || and !, and group with parentheses.
+// Threads are selected by matching their OP, which carries the thread wide fields.
+type Filter struct {
+	expr  string
+	match func(*Post) bool
+}
+
+// Custom error to indicate a filter expression couldn't be compiled.
+type FilterSyntaxError struct {
+	// The expression being compiled.
+	Expr string
+	// Byte offset of the problem.
+	Offset int
+	// What went wrong.
+	Msg string
+}
+
+// Pretty print dat error yo.
+func (e FilterSyntaxError) Error() string {
+	return fmt.Sprintf("Bad filter %q at offset %d: %s", e.Expr, e.Offset, e.Msg)
+}
+
+// String fields available to filters.
+var filterStringFields = map[string]func(*Post) string{
+	"subject":  func(p *Post) string { return commentText(p.Subject) },
+	"comment":  func(p *Post) string { return commentText(p.Comment) },
+	"name":     func(p *Post) string { return p.Name },
+	"trip":     func(p *Post) string { return p.TripCode },
+	"id":       func(p *Post) string { return p.AdminId },
+	"capcode":  func(p *Post) string { return p.AdminType },
+	"country":  func(p *Post) string { return p.CountryCode },
+	"filename": func(p *Post) string { return p.OrigFileName },
+	"ext":      func(p *Post) string { return p.FileExt },
+	"md5":      func(p *Post) string { return p.FileMD5 },
+	"tag":      func(p *Post) string { return p.Tag },
+}
+
+// Numeric fields available to filters.
+var filterNumberFields = map[string]func(*Post) float64{
+	"no":      func(p *Post) float64 { return float64(p.PostNumber) },
+	"resto":   func(p *Post) float64 { return float64(p.ReplyTo) },
+	"time":    func(p *Post) float64 { return float64(p.UnixTime) },
+	"replies": func(p *Post) float64 { return float64(p.ReplyCount) },
+	"images":  func(p *Post) float64 { return float64(p.ImageCount) },
+	"fsize":   func(p *Post) float64 { return float64(p.FileSize) },
+	"w":       func(p *Post) float64 { return float64(p.FileWidth) },
+	"h":       func(p *Post) float64 { return float64(p.FileHeight) },
+}
+
+// Boolean fields available to filters.
+var filterBoolFields = map[string]func(*Post) bool{
+	"op":          func(p *Post) bool { return p.ReplyTo == 0 },
+	"file":        func(p *Post) bool { return p.HasFile },
+	"filedeleted": func(p *Post) bool { return p.FileDeleted },
+	"spoiler":     func(p *Post) bool { return p.Spoiler },
+	"sticky":      func(p *Post) bool { return p.Sticky },
+	"closed":      func(p *Post) bool { return p.Closed },
+	"archived":    func(p *Post) bool { return p.Archived },
+	"bumplimit":   func(p *Post) bool { return p.BumpLimit },
+	"imagelimit":  func(p *Post) bool { return p.ImageLimit },
+}
+
+// Compile a filter expression.
+func CompileFilter(expr string) (*Filter, error) {
+	p := &filterParser{expr: expr}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %q", p.tok.text)
+	}
+
+	return &Filter{expr: expr, match: match}, nil
+}
+
+// Like CompileFilter but panics if the expression is invalid.
+func MustCompileFilter(expr string) *Filter {
+	f, err := CompileFilter(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Does the post match the filter?
+func (f *Filter) Match(p *Post) bool {
+	return f.match(p)
+}
+
+// Does the thread's OP match the filter?
+func (f *Filter) MatchThread(t *Thread) bool {
+	return len(t.Posts) > 0 && f.match(&t.Posts[0])
+}
+
+// The source of the expression.
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// The posts in the thread the predicate accepts.
+// The returned pointers refer to the thread's own posts.
+func (t *Thread) Filter(match func(*Post) bool) []*Post {
+	var posts []*Post
+	for i := range t.Posts {
+		if match(&t.Posts[i]) {
+			posts = append(posts, &t.Posts[i])
+		}
+	}
+	return posts
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+type filterParser struct {
+	expr string
+	pos  int
+	tok  token
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return FilterSyntaxError{p.expr, p.tok.offset, fmt.Sprintf(format, args...)}
+}
+
+// Advance to the next token.
+func (p *filterParser) next() error {
+	for p.pos < len(p.expr) && unicode.IsSpace(rune(p.expr[p.pos])) {
+		p.pos++
+	}
+
+	start := p.pos
+	p.tok = token{offset: start}
+	if p.pos >= len(p.expr) {
+		p.tok.kind = tokEOF
+		return nil
+	}
+
+	rest := p.expr[p.pos:]
+	c := rest[0]
+	switch {
+	case strings.HasPrefix(rest, "&&"):
+		p.tok.kind = tokAnd
+		p.pos += 2
+	case strings.HasPrefix(rest, "||"):
+		p.tok.kind = tokOr
+		p.pos += 2
+	case strings.HasPrefix(rest, "=="), strings.HasPrefix(rest, "!="), strings.HasPrefix(rest, "<="),
+		strings.HasPrefix(rest, ">="), strings.HasPrefix(rest, "!~"):
+		p.tok.kind = tokOp
+		p.pos += 2
+	case c == '<' || c == '>' || c == '~':
+		p.tok.kind = tokOp
+		p.pos++
+	case c == '!':
+		p.tok.kind = tokNot
+		p.pos++
+	case c == '(':
+		p.tok.kind = tokLParen
+		p.pos++
+	case c == ')':
+		p.tok.kind = tokRParen
+		p.pos++
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.expr) && p.expr[end] != '"' {
+			if p.expr[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.expr) {
+			return p.errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(p.expr[p.pos : end+1])
+		if err != nil {
+			return p.errorf("bad string: %v", err)
+		}
+		p.tok.kind = tokString
+		p.pos = end + 1
+		p.tok.text = s
+		return nil
+	case c >= '0' && c <= '9' || c == '-' || c == '.':
+		for p.pos < len(p.expr) && strings.IndexByte("0123456789.-eE", p.expr[p.pos]) >= 0 {
+			p.pos++
+		}
+		p.tok.kind = tokNumber
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.expr) && (p.expr[p.pos] == '_' || unicode.IsLetter(rune(p.expr[p.pos])) || unicode.IsDigit(rune(p.expr[p.pos]))) {
+			p.pos++
+		}
+		p.tok.kind = tokIdent
+	default:
+		return p.errorf("unexpected character %q", c)
+	}
+
+	p.tok.text = p.expr[start:p.pos]
+	return nil
+}
+
+// or := and ("||" and)*
+func (p *filterParser) parseOr() (func(*Post) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOr {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(post *Post) bool { return l(post) || right(post) }
+	}
+	return left, nil
+}
+
+// and := unary ("&&" unary)*
+func (p *filterParser) parseAnd() (func(*Post) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokAnd {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(post *Post) bool { return l(post) && right(post) }
+	}
+	return left, nil
+}
+
+// unary := "!" unary | "(" or ")" | comparison
+func (p *filterParser) parseUnary() (func(*Post) bool, error) {
+	switch p.tok.kind {
+	case tokNot:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(post *Post) bool { return !inner(post) }, nil
+	case tokLParen:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected )")
+		}
+		return inner, p.next()
+	case tokIdent:
+		return p.parseComparison()
+	}
+	if p.tok.kind == tokEOF {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	return nil, p.errorf("unexpected %q", p.tok.text)
+}
+
+// comparison := field [op literal]
+func (p *filterParser) parseComparison() (func(*Post) bool, error) {
+	field := p.tok
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(field.text)
+	if p.tok.kind == tokIdent && strings.EqualFold(p.tok.text, "contains") {
+		p.tok.kind, p.tok.text = tokOp, "contains"
+	}
+
+	if get, ok := filterBoolFields[name]; ok {
+		if p.tok.kind == tokOp {
+			return nil, p.errorf("%s is a boolean and can't be compared", field.text)
+		}
+		return get, nil
+	}
+
+	if p.tok.kind != tokOp {
+		if _, ok := filterStringFields[name]; ok {
+			return nil, p.errorf("expected an operator after %s", field.text)
+		}
+		if _, ok := filterNumberFields[name]; ok {
+			return nil, p.errorf("expected an operator after %s", field.text)
+		}
+		p.tok = field
+		return nil, p.errorf("unknown field %s", field.text)
+	}
+	op := p.tok
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	lit := p.tok
+
+	if get, ok := filterStringFields[name]; ok {
+		if lit.kind != tokString {
+			return nil, p.errorf("%s must be compared with a string", field.text)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch op.text {
+		case "==":
+			return func(post *Post) bool { return get(post) == lit.text }, nil
+		case "!=":
+			return func(post *Post) bool { return get(post) != lit.text }, nil
+		case "contains":
+			query := FoldText(lit.text)
+			return func(post *Post) bool { return strings.Contains(FoldText(get(post)), query) }, nil
+		case "~", "!~":
+			re, err := regexp.Compile(lit.text)
+			if err != nil {
+				p.tok = lit
+				return nil, p.errorf("bad regular expression: %v", err)
+			}
+			negate := op.text == "!~"
+			return func(post *Post) bool { return re.MatchString(get(post)) != negate }, nil
+		}
+		p.tok = op
+		return nil, p.errorf("%s can't be used on strings", op.text)
+	}
+
+	if get, ok := filterNumberFields[name]; ok {
+		if lit.kind != tokNumber {
+			return nil, p.errorf("%s must be compared with a number", field.text)
+		}
+		n, err := strconv.ParseFloat(lit.text, 64)
+		if err != nil {
+			return nil, p.errorf("bad number %s", lit.text)
+		}
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		switch op.text {
+		case "==":
+			return func(post *Post) bool { return get(post) == n }, nil
+		case "!=":
+			return func(post *Post) bool { return get(post) != n }, nil
+		case "<":
+			return func(post *Post) bool { return get(post) < n }, nil
+		case "<=":
+			return func(post *Post) bool { return get(post) <= n }, nil
+		case ">":
+			return func(post *Post) bool { return get(post) > n }, nil
+		case ">=":
+			return func(post *Post) bool { return get(post) >= n }, nil
+		}
+		p.tok = op
+		return nil, p.errorf("%s can't be used on numbers", op.text)
+	}
+
+	p.tok = field
+	return nil, p.errorf("unknown field %s", field.text)
+}
@@ -0,0 +1,85 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	op := &Post{Subject: "/ppg/ - Python &amp; friends", Meta: Meta{PostNumber: 1, ReplyCount: 120, ImageCount: 4}}
+	sticky := &Post{Subject: "Rules", Meta: Meta{PostNumber: 2, Sticky: true, ReplyCount: 500}}
+	reply := &Post{Comment: "&gt;using python<br>ngmi", Meta: Meta{PostNumber: 3, ReplyTo: 1, HasFile: true, FileExt: ".png"}}
+
+	tests := []struct {
+		expr    string
+		post    *Post
+		matches bool
+	}{
+		{`subject ~ "ppg|python" && replies > 50 && !sticky`, op, true},
+		{`subject ~ "ppg|python" && replies > 50 && !sticky`, sticky, false},
+		{`subject ~ "&"`, op, true},
+		{`comment ~ "(?i)^>using"`, reply, true},
+		{`comment !~ "python"`, reply, false},
+		{`op`, op, true},
+		{`op`, reply, false},
+		{`file && ext == ".png"`, reply, true},
+		{`!(file || sticky)`, op, true},
+		{`sticky || replies >= 120 && images < 1`, op, false},
+		{`no != 1`, op, false},
+		{`resto == 1`, reply, true},
+		{`comment contains "PYTHON"`, reply, true},
+		{`subject CONTAINS "pythön" && replies > 50`, op, true},
+		{`comment contains "ruby"`, reply, false},
+	}
+
+	for _, test := range tests {
+		f, err := CompileFilter(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", test.expr, err)
+		}
+		if matches := f.Match(test.post); matches != test.matches {
+			t.Fatalf("%s on %d: %v != %v", test.expr, test.post.PostNumber, matches, test.matches)
+		}
+	}
+}
+
+func TestFilterSyntaxErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`subject`,
+		`replies > "50"`,
+		`subject > "a"`,
+		`sticky == 1`,
+		`nope == 1`,
+		`(op`,
+		`op &&`,
+		`subject ~ "("`,
+		`subject == "unterminated`,
+		`op op`,
+		`op # 1`,
+		`replies contains "5"`,
+		`sticky contains "a"`,
+	}
+
+	for _, expr := range tests {
+		_, err := CompileFilter(expr)
+		if _, ok := err.(FilterSyntaxError); !ok {
+			t.Fatalf("%s: unexpected error %v", expr, err)
+		}
+	}
+}
+
+func TestThreadFilter(t *testing.T) {
+	thread := &Thread{Posts: []Post{
+		{Meta: Meta{PostNumber: 1}},
+		{Meta: Meta{PostNumber: 2, ReplyTo: 1, HasFile: true}},
+		{Meta: Meta{PostNumber: 3, ReplyTo: 1}},
+	}}
+
+	posts := thread.Filter(MustCompileFilter("file").Match)
+	if len(posts) != 1 || posts[0].PostNumber != 2 {
+		t.Fatalf("bad filter result %v", posts)
+	}
+	if !MustCompileFilter("op && no == 1").MatchThread(thread) {
+		t.Fatal("thread didn't match")
+	}
+}
@@ -0,0 +1,57 @@
+package fourchan
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Plain letters for the accented and combined Latin letters of Latin-1 and Latin Extended-A, lower case only.
+var foldLetters = map[string]string{
+	"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ", "h": "ĥħ",
+	"i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķĸ", "l": "ĺļľŀł", "n": "ñńņňŉŋ", "o": "òóôõöøōŏő", "r": "ŕŗř",
+	"s": "śŝşšſ", "t": "ţťŧ", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ", "z": "źżž",
+	"ae": "æ", "oe": "œ", "ss": "ß", "th": "þ", "ij": "ĳ",
+}
+
+// foldLetters by letter.
+var foldTable = func() map[rune]string {
+	table := map[rune]string{}
+	for plain, letters := range foldLetters {
+		for _, r := range letters {
+			table[r] = plain
+		}
+	}
+	return table
+}()
+
+// Fold text for matching regardless of case and accents: letters are lower cased, accented Latin letters
+// lose their accents, e.g. Café becomes cafe and Straße strasse, and combining marks are dropped.
+// The result is for comparing, not for showing.
+func FoldText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if plain, ok := foldTable[r]; ok {
+			b.WriteString(plain)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Does text contain query, ignoring case and accents? See FoldText.
+func ContainsFold(text, query string) bool {
+	return strings.Contains(FoldText(text), FoldText(query))
+}
+
+// Match posts whose subject or comment contain query, ignoring case and accents, for Thread.Filter.
+func TextMatcher(query string) func(*Post) bool {
+	query = FoldText(query)
+	return func(p *Post) bool {
+		return strings.Contains(FoldText(commentText(p.Subject)), query) || strings.Contains(FoldText(commentText(p.Comment)), query)
+	}
+}
@@ -0,0 +1,35 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestFoldText(t *testing.T) {
+	for _, test := range []struct {
+		text, want string
+	}{
+		{"Café", "cafe"},
+		{"Cafe\u0301", "cafe"},
+		{"STRAẞE Straße", "strasse strasse"},
+		{"Ærøskøbing", "aeroskobing"},
+		{"Łódź", "lodz"},
+		{"日本語 OK", "日本語 ok"},
+	} {
+		if got := FoldText(test.text); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	if !ContainsFold("Ordered a CRÈME brûlée", "creme brulee") || ContainsFold("creme", "crème brûlée") {
+		t.Error("bad ContainsFold")
+	}
+
+	thread := &Thread{Posts: []Post{
+		{Subject: "Résumé thread", Meta: Meta{PostNumber: 1}},
+		{Comment: "my <b>RESUME</b>", Meta: Meta{PostNumber: 2}},
+		{Comment: "nothing here", Meta: Meta{PostNumber: 3}},
+	}}
+	if posts := thread.Filter(TextMatcher("resume")); len(posts) != 2 || posts[1].PostNumber != 2 {
+		t.Fatalf("bad matches %+v", posts)
+	}
+}
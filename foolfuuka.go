@@ -0,0 +1,377 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An archive running FoolFuuka, such as archived.moe, 4plebs.org or desuarchive.org.
+type FoolFuuka struct {
+	// Where the archive is served from, e.g. https://archived.moe
+	URL string
+	// The boards the archive keeps, empty to ask it about every board.
+	Boards []string
+
+	client *Client
+}
+
+// Create a FoolFuuka archive at the given URL.
+// Requests go through their own client built from opts, so they don't share 4chan's rate limit.
+func NewFoolFuuka(archiveURL string, boards []string, opts ...Option) *FoolFuuka {
+	return &FoolFuuka{URL: strings.TrimRight(archiveURL, "/"), Boards: boards, client: NewClient(opts...)}
+}
+
+// The host of the archive.
+func (f *FoolFuuka) Name() string {
+	if u, err := url.Parse(f.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return f.URL
+}
+
+// Does the archive keep the board?
+func (f *FoolFuuka) hasBoard(board string) bool {
+	if len(f.Boards) == 0 {
+		return true
+	}
+	for _, b := range f.Boards {
+		if b == board {
+			return true
+		}
+	}
+	return false
+}
+
+// The URL of one of the archive's API endpoints.
+func (f *FoolFuuka) endpointURL(endpoint string, query url.Values) string {
+	return f.URL + "/_/api/chan/" + endpoint + "/?" + query.Encode()
+}
+
+// Call one of the archive's API endpoints.
+// Returns false when the archive answers 404 or with an error object, which is how it reports missing things.
+func (f *FoolFuuka) get(ctx context.Context, endpoint string, query url.Values) (json.RawMessage, bool, error) {
+	client := f.client
+	if client == nil {
+		client = defaultClient()
+	}
+
+	var resp json.RawMessage
+	_, err := client.getJSON(ctx, f.endpointURL(endpoint, query), &resp)
+	if isNotFound(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	// e.g. {"error": "Thread not found."}
+	var failed struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(resp, &failed) == nil && failed.Error != "" {
+		return nil, false, nil
+	}
+	return resp, true, nil
+}
+
+// Load a thread through the archive's /_/api/chan/thread/ endpoint.
+// Implements ArchiveProvider.
+func (f *FoolFuuka) LoadThread(ctx context.Context, board, id string) (*Thread, error) {
+	if !f.hasBoard(board) {
+		return nil, ThreadNotFoundError{board, id}
+	}
+
+	query := url.Values{"board": {board}, "num": {id}}
+	resp, found, err := f.get(ctx, "thread", query)
+	if err != nil {
+		return nil, err
+	}
+	var threads map[string]fuukaThread
+	if found {
+		if err = json.Unmarshal(resp, &threads); err != nil {
+			return nil, DecodeError{URL: f.endpointURL("thread", query), Err: err}
+		}
+	}
+	ft, ok := threads[id]
+	if !ok {
+		return nil, ThreadNotFoundError{board, id}
+	}
+	thread, err := ft.thread()
+	if err != nil {
+		return nil, DecodeError{URL: f.endpointURL("thread", query), Err: err}
+	}
+	thread.Board = board
+
+	return thread, nil
+}
+
+// Most pages of search results FindByMD5 asks for.
+const maxFuukaSearchPages = 50
+
+// Search the archive for posts with the given file through /_/api/chan/search/.
+// Stops at the first page that has nothing new, since some archives serve the last page again
+// rather than an empty one, and after maxFuukaSearchPages either way.
+// Implements ArchiveSearcher.
+func (f *FoolFuuka) FindByMD5(ctx context.Context, board, md5 string) ([]PostLocation, error) {
+	if board != "" && !f.hasBoard(board) {
+		return nil, nil
+	}
+
+	// The archive wants the hash in URL safe base64 without padding.
+	hash := strings.TrimRight(strings.NewReplacer("+", "-", "/", "_").Replace(md5), "=")
+	query := url.Values{"image": {hash}}
+	if board != "" {
+		query.Set("boards", board)
+	} else if len(f.Boards) > 0 {
+		query.Set("boards", strings.Join(f.Boards, "."))
+	}
+
+	var locations []PostLocation
+	// By board and post number, e.g. "g/10".
+	seen := map[string]bool{}
+	for page := 1; page <= maxFuukaSearchPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+		resp, found, err := f.get(ctx, "search", query)
+		if err != nil {
+			return nil, err
+		}
+		var results map[string]struct {
+			Posts []fuukaPost `json:"posts"`
+		}
+		if found {
+			if err = json.Unmarshal(resp, &results); err != nil {
+				return nil, err
+			}
+		}
+		fresh := false
+		for i := range results["0"].Posts {
+			fp := &results["0"].Posts[i]
+			key := fmt.Sprintf("%s/%d", fp.Board.Shortname, fp.Num)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			fresh = true
+			locations = append(locations, fp.location())
+		}
+		if !fresh {
+			break
+		}
+	}
+	return locations, nil
+}
+
+// Look a post up through /_/api/chan/post/.
+// Implements ArchiveSearcher.
+func (f *FoolFuuka) FindPost(ctx context.Context, board string, no PostID) (PostLocation, error) {
+	if !f.hasBoard(board) {
+		return PostLocation{}, PostNotFoundError{board, no}
+	}
+
+	resp, found, err := f.get(ctx, "post", url.Values{"board": {board}, "num": {strconv.FormatUint(no, 10)}})
+	if err != nil {
+		return PostLocation{}, err
+	}
+	if !found {
+		return PostLocation{}, PostNotFoundError{board, no}
+	}
+
+	var fp fuukaPost
+	if err = json.Unmarshal(resp, &fp); err != nil {
+		return PostLocation{}, err
+	}
+	location := fp.location()
+	if location.Board == "" {
+		location.Board = board
+	}
+	return location, nil
+}
+
+// A number FoolFuuka sends as either a string or a number.
+type fuukaInt uint64
+
+// Custom unmarshaler for a fuukaInt.
+// Accepts "123", 123 and null.
+func (n *fuukaInt) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil {
+		return err
+	}
+	*n = fuukaInt(v)
+	return nil
+}
+
+// A post as FoolFuuka serves it.
+type fuukaPost struct {
+	Num              fuukaInt    `json:"num"`
+	Subnum           fuukaInt    `json:"subnum"`
+	ThreadNum        fuukaInt    `json:"thread_num"`
+	OP               fuukaInt    `json:"op"`
+	Timestamp        fuukaInt    `json:"timestamp"`
+	TimestampExpired fuukaInt    `json:"timestamp_expired"`
+	FourchanDate     string      `json:"fourchan_date"`
+	Capcode          string      `json:"capcode"`
+	Name             string      `json:"name"`
+	Trip             string      `json:"trip"`
+	Title            string      `json:"title"`
+	Comment          string      `json:"comment"`
+	PosterHash       string      `json:"poster_hash"`
+	Country          string      `json:"poster_country"`
+	CountryName      string      `json:"poster_country_name"`
+	Sticky           fuukaInt    `json:"sticky"`
+	Locked           fuukaInt    `json:"locked"`
+	Media            *fuukaMedia `json:"media"`
+	Board            struct {
+		Shortname string `json:"shortname"`
+	} `json:"board"`
+}
+
+// The file attached to a FoolFuuka post.
+type fuukaMedia struct {
+	// The name 4chan served the file under, e.g. 1456789012345.jpg
+	MediaOrig     string   `json:"media_orig"`
+	MediaFilename string   `json:"media_filename"`
+	MediaHash     string   `json:"media_hash"`
+	MediaSize     fuukaInt `json:"media_size"`
+	MediaW        fuukaInt `json:"media_w"`
+	MediaH        fuukaInt `json:"media_h"`
+	PreviewW      fuukaInt `json:"preview_w"`
+	PreviewH      fuukaInt `json:"preview_h"`
+	Spoiler       fuukaInt `json:"spoiler"`
+}
+
+// A thread as FoolFuuka serves it, replies are keyed by post number.
+type fuukaThread struct {
+	OP fuukaPost `json:"op"`
+	// An object of posts, or an empty array when there are no replies.
+	Posts json.RawMessage `json:"posts"`
+}
+
+// FoolFuuka's single letter capcodes.
+var fuukaCapcodes = map[string]string{
+	"M": "mod", "A": "admin", "D": "developer", "V": "verified", "F": "founder", "G": "manager",
+}
+
+// Normalize the archived thread into the live API's shape.
+// Fails when the replies don't decode, rather than passing the OP off as the whole thread.
+func (ft *fuukaThread) thread() (*Thread, error) {
+	replies := map[string]fuukaPost{}
+	if len(ft.Posts) > 0 && ft.Posts[0] == '{' {
+		if err := json.Unmarshal(ft.Posts, &replies); err != nil {
+			return nil, err
+		}
+	}
+
+	posts := []Post{ft.OP.post()}
+	images := 0
+	for _, reply := range replies {
+		// Ghost posts were made on the archive after the thread died.
+		if reply.Subnum != 0 {
+			continue
+		}
+		post := reply.post()
+		if post.HasFile {
+			images++
+		}
+		posts = append(posts, post)
+	}
+	sort.Slice(posts[1:], func(i, j int) bool { return posts[i+1].PostNumber < posts[j+1].PostNumber })
+
+	op := &posts[0]
+	op.Archived = true
+	op.ArchivedOn = uint64(ft.OP.TimestampExpired)
+	op.ReplyCount = len(posts) - 1
+	op.ImageCount = images
+
+	return &Thread{Posts: posts}, nil
+}
+
+// Convert to a Post as the live API would have served it.
+func (fp *fuukaPost) post() Post {
+	p := Post{
+		Subject: html.EscapeString(fp.Title),
+		Comment: fuukaCommentHTML(fp.Comment),
+		Meta: Meta{
+			PostNumber:  uint64(fp.Num),
+			UnixTime:    uint64(fp.Timestamp),
+			Time:        fp.FourchanDate,
+			AdminId:     fp.PosterHash,
+			AdminType:   fuukaCapcodes[fp.Capcode],
+			Name:        fp.Name,
+			TripCode:    fp.Trip,
+			CountryCode: fp.Country,
+			Country:     fp.CountryName,
+			Sticky:      fp.Sticky != 0,
+			Closed:      fp.Locked != 0,
+		},
+	}
+	if fp.OP == 0 {
+		p.ReplyTo = uint64(fp.ThreadNum)
+	}
+
+	if m := fp.Media; m != nil && m.MediaOrig != "" {
+		ext := path.Ext(m.MediaOrig)
+		tim, _ := strconv.ParseUint(strings.TrimSuffix(m.MediaOrig, ext), 10, 64)
+		p.HasFile = tim != 0
+		p.RenamedFileName = tim
+		p.FileExt = ext
+		p.OrigFileName = strings.TrimSuffix(m.MediaFilename, path.Ext(m.MediaFilename))
+		p.FileMD5 = m.MediaHash
+		p.FileSize = int(m.MediaSize)
+		p.FileWidth = int(m.MediaW)
+		p.FileHeight = int(m.MediaH)
+		p.ThumbnailWidth = int(m.PreviewW)
+		p.ThumbnailHeight = int(m.PreviewH)
+		p.Spoiler = m.Spoiler != 0
+		p.FullOrigFileName = p.OrigFileName + p.FileExt
+		if p.HasFile {
+			p.FullNewFileName = m.MediaOrig
+		}
+	}
+
+	return p
+}
+
+// Where the post is, for search results.
+func (fp *fuukaPost) location() PostLocation {
+	post := fp.post()
+	return PostLocation{Board: fp.Board.Shortname, Thread: uint64(fp.ThreadNum), Post: &post}
+}
+
+// Matches an escaped >>123 that isn't part of a >>>/board/ link.
+var fuukaQuoteRegex = regexp.MustCompile(`(^|[^;])&gt;&gt;(\d+)`)
+
+// Matches a line starting with an escaped >>123.
+var fuukaLeadingQuoteRegex = regexp.MustCompile(`^&gt;&gt;\d`)
+
+// Turn FoolFuuka's plain text comments back into 4chan's comment HTML.
+func fuukaCommentHTML(comment string) string {
+	if comment == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.Replace(comment, "\r\n", "\n", -1), "\n")
+	for i, line := range lines {
+		line = html.EscapeString(line)
+		greentext := strings.HasPrefix(line, "&gt;") && !fuukaLeadingQuoteRegex.MatchString(line)
+		line = fuukaQuoteRegex.ReplaceAllString(line, `$1<a href="#p$2" class="quotelink">&gt;&gt;$2</a>`)
+		if greentext {
+			line = fmt.Sprintf(`<span class="quote">%s</span>`, line)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "<br>")
+}
@@ -0,0 +1,161 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const fuukaThreadJSON = `{"570368": {
+	"op": {"num": "570368", "subnum": "0", "thread_num": "570368", "op": "1", "timestamp": 1456789012,
+		"timestamp_expired": "1456799012", "capcode": "N", "name": "Anonymous", "trip": null, "title": "Rate my desk",
+		"comment": "Post yours", "poster_hash": "abcd1234", "sticky": "0", "locked": "1",
+		"media": {"media_orig": "1456789012345.jpg", "media_filename": "desk.jpg", "media_hash": "XUFAKrxLKna5cZ2REBfFkg==",
+			"media_size": "12345", "media_w": "800", "media_h": "600", "preview_w": "250", "preview_h": "187", "spoiler": "0"}},
+	"posts": {
+		"570370": {"num": "570370", "subnum": "0", "thread_num": "570368", "op": "0", "timestamp": 1456789100,
+			"capcode": "M", "comment": ">>570368\n>implying\nnice", "media": null},
+		"570369": {"num": "570369", "subnum": "0", "thread_num": "570368", "op": "0", "timestamp": 1456789050,
+			"comment": "first", "media": null},
+		"570370_1": {"num": "570370", "subnum": "1", "thread_num": "570368", "op": "0", "comment": "ghost"}
+	}
+}}`
+
+func TestFoolFuukaLoadThread(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_/api/chan/thread/" || r.URL.Query().Get("board") != "g" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("num") {
+		case "570368":
+		case "2":
+			fmt.Fprint(w, `{"2": {"op": {"num": "2"}, "posts": {"3": {"num": ["not", "a", "number"]}}}}`)
+			return
+		default:
+			fmt.Fprint(w, `{"error": "Thread not found."}`)
+			return
+		}
+		fmt.Fprint(w, fuukaThreadJSON)
+	}))
+	defer server.Close()
+
+	archive := NewFoolFuuka(server.URL+"/", []string{"g"}, WithRateLimit(0))
+	thread, err := archive.LoadThread(context.Background(), "g", "570368")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread.Posts) != 3 || thread.Posts[1].PostNumber != 570369 || thread.Posts[2].PostNumber != 570370 {
+		t.Fatalf("bad posts %+v", thread.Posts)
+	}
+
+	op := thread.Posts[0]
+	if op.Subject != "Rate my desk" || op.ReplyTo != 0 || !op.Closed || !op.Archived || op.ArchivedOn != 1456799012 || op.ReplyCount != 2 {
+		t.Fatalf("bad op %+v", op)
+	}
+	if !op.HasFile || op.RenamedFileName != 1456789012345 || op.FileExt != ".jpg" || op.FullOrigFileName != "desk.jpg" ||
+		op.FileWidth != 800 || op.ThumbnailHeight != 187 || op.FileSize != 12345 {
+		t.Fatalf("bad file %+v", op.Meta)
+	}
+
+	reply := thread.Posts[2]
+	if reply.ReplyTo != 570368 || reply.AdminType != "mod" || reply.HasFile {
+		t.Fatalf("bad reply %+v", reply)
+	}
+	want := `<a href="#p570368" class="quotelink">&gt;&gt;570368</a><br><span class="quote">&gt;implying</span><br>nice`
+	if reply.Comment != want {
+		t.Fatalf("%q != %q", reply.Comment, want)
+	}
+	if quoted := reply.QuotedPosts(); len(quoted) != 1 || quoted[0] != 570368 {
+		t.Fatalf("bad quotes %v", quoted)
+	}
+
+	if _, err = archive.LoadThread(context.Background(), "g", "1"); err != (ThreadNotFoundError{"g", "1"}) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err = archive.LoadThread(context.Background(), "g", "2"); err == nil {
+		t.Fatal("replies that don't decode were dropped")
+	}
+	if _, ok := err.(DecodeError); !ok {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if _, err = archive.LoadThread(context.Background(), "v", "570368"); err != (ThreadNotFoundError{"v", "570368"}) {
+		t.Fatalf("unexpected error for unarchived board %v", err)
+	}
+	if archive.Name() != server.Listener.Addr().String() {
+		t.Fatalf("bad name %q", archive.Name())
+	}
+}
+
+func TestFoolFuukaSearch(t *testing.T) {
+	var hashes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/_/api/chan/search/":
+			hashes = append(hashes, query.Get("image"))
+			if query.Get("page") != "1" {
+				fmt.Fprint(w, `{"error": "No results found."}`)
+				return
+			}
+			fmt.Fprint(w, `{"0": {"posts": [
+				{"num": "10", "thread_num": "9", "op": "0", "board": {"shortname": "g"},
+				 "media": {"media_orig": "1456789012345.png", "media_hash": "a+b/cQ=="}},
+				{"num": "20", "thread_num": "20", "op": "1", "board": {"shortname": "w"},
+				 "media": {"media_orig": "1456789012346.png", "media_hash": "a+b/cQ=="}}
+			]}, "meta": {"total_found": 2}}`)
+		case "/_/api/chan/post/":
+			if query.Get("num") != "10" {
+				fmt.Fprint(w, `{"error": "Post not found."}`)
+				return
+			}
+			fmt.Fprint(w, `{"num": "10", "thread_num": "9", "op": "0", "comment": "hi", "board": {"shortname": "g"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	archive := NewFoolFuuka(server.URL, nil, WithRateLimit(0))
+	client := NewClient(WithRateLimit(0), WithArchiveFallback(fakeArchive{}, archive, archive))
+
+	found, err := client.FindPostsByMD5(context.Background(), "", "a+b/cQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Board != "g" || found[0].Thread != 9 || found[0].Post.PostNumber != 10 || found[1].Board != "w" {
+		t.Fatalf("bad results %+v", found)
+	}
+	if hashes[0] != "a-b_cQ" {
+		t.Fatalf("hash not made URL safe: %q", hashes[0])
+	}
+
+	location, err := client.FindPost(context.Background(), "g", 10)
+	if err != nil || location.Thread != 9 || location.Post.PlainText() != "hi" {
+		t.Fatalf("bad location %+v %v", location, err)
+	}
+	if _, err = client.FindPost(context.Background(), "g", 11); err != (PostNotFoundError{"g", 11}) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFoolFuukaSearchRepeatedPage(t *testing.T) {
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Serves the same results for every page.
+		pages++
+		fmt.Fprint(w, `{"0": {"posts": [{"num": "10", "thread_num": "9", "op": "0", "board": {"shortname": "g"}}]}}`)
+	}))
+	defer server.Close()
+
+	archive := NewFoolFuuka(server.URL, nil, WithRateLimit(0))
+	found, err := archive.FindByMD5(context.Background(), "g", "a+b/cQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || pages != 2 {
+		t.Fatalf("got %d results from %d pages, want 1 from 2", len(found), pages)
+	}
+}
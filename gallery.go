@@ -0,0 +1,159 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"strings"
+)
+
+// Options for building a gallery.
+type GalleryOptions struct {
+	// The title of the gallery, defaults to the subject of the first thread.
+	Title string
+	// Link to files saved locally under this path instead of the media host,
+	// e.g. "images" for images/1456789012345.jpg. Thumbnails are expected as 1456789012345s.jpg.
+	MediaPath string
+	// Link to files through a MediaServer mounted here instead of the media host, e.g. "/media"
+	// for /media/g/1456789012345.jpg. MediaPath takes precedence.
+	MediaRoute string
+	// Leave out spoilered files.
+	SkipSpoilers bool
+	// Link to files on this client's media host, see WithMediaURL. Defaults to DefaultClient.
+	Client *Client
+}
+
+// A single file in a gallery.
+type GalleryItem struct {
+	Board string `json:"board"`
+	// The post number of the OP.
+	Thread uint64 `json:"thread"`
+	// The post the file is attached to.
+	Post uint64 `json:"post"`
+	// The original name of the file, including the extension.
+	FileName string `json:"filename"`
+	// Where the full file is.
+	URL string `json:"url"`
+	// Where the thumbnail is.
+	ThumbnailURL string `json:"thumbnail_url"`
+	// Where the post is on the site.
+	PostURL string `json:"post_url"`
+
+	Width           int    `json:"w"`
+	Height          int    `json:"h"`
+	ThumbnailWidth  int    `json:"tn_w"`
+	ThumbnailHeight int    `json:"tn_h"`
+	FileSize        int    `json:"fsize"`
+	FileMD5         string `json:"md5"`
+	Spoiler         bool   `json:"spoiler,omitempty"`
+}
+
+// The files of one or more threads, in the order they were posted.
+type Gallery struct {
+	Title string        `json:"title"`
+	Items []GalleryItem `json:"items"`
+}
+
+// Collect the files in threads into a gallery.
+// Deleted files are left out.
+func BuildGallery(threads []*Thread, opts *GalleryOptions) *Gallery {
+	if opts == nil {
+		opts = &GalleryOptions{}
+	}
+	client := opts.Client
+	if client == nil {
+		client = defaultClient()
+	}
+	gallery := &Gallery{Title: opts.Title, Items: []GalleryItem{}}
+	if gallery.Title == "" && len(threads) > 0 {
+		gallery.Title = threadTitle(threads[0])
+	}
+
+	for _, thread := range threads {
+		op := firstPostNumber(thread)
+		for i := range thread.Posts {
+			post := &thread.Posts[i]
+			if !post.HasFile || post.FileDeleted || (opts.SkipSpoilers && post.Spoiler) {
+				continue
+			}
+
+			gallery.Items = append(gallery.Items, galleryItem(client, thread.Board, op, post, opts))
+		}
+	}
+
+	return gallery
+}
+
+// The gallery item for a post's file.
+func galleryItem(client *Client, board string, op uint64, post *Post, opts *GalleryOptions) GalleryItem {
+	item := GalleryItem{
+		Board:           board,
+		Thread:          op,
+		Post:            post.PostNumber,
+		FileName:        post.FileName(),
+		URL:             client.ImageURL(board, post),
+		ThumbnailURL:    client.ThumbnailURL(board, post),
+		PostURL:         postURL(board, op, post.PostNumber),
+		Width:           post.FileWidth,
+		Height:          post.FileHeight,
+		ThumbnailWidth:  post.ThumbnailWidth,
+		ThumbnailHeight: post.ThumbnailHeight,
+		FileSize:        post.FileSize,
+		FileMD5:         post.FileMD5,
+		Spoiler:         post.Spoiler,
+	}
+	if opts.MediaPath != "" {
+		item.URL = path.Join(opts.MediaPath, post.mediaName())
+		item.ThumbnailURL = path.Join(opts.MediaPath, post.thumbnailName())
+	} else if opts.MediaRoute != "" {
+		item.URL = post.MediaPath(opts.MediaRoute, board)
+		item.ThumbnailURL = post.ThumbnailPath(opts.MediaRoute, board)
+	}
+	return item
+}
+
+// Write the gallery as a JSON manifest.
+func (g *Gallery) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// Render the gallery as a standalone HTML page, a grid of thumbnails linking to the full files.
+func (g *Gallery) WriteHTML(w io.Writer) error {
+	var b strings.Builder
+	title := html.EscapeString(g.Title)
+	fmt.Fprintf(&b, galleryHeader, title, title, len(g.Items))
+
+	for i := range g.Items {
+		item := &g.Items[i]
+		fmt.Fprintf(&b, "<figure><a href=\"%s\"><img src=\"%s\" width=\"%d\" height=\"%d\" alt=\"%s\" loading=\"lazy\"></a>\n",
+			html.EscapeString(item.URL), html.EscapeString(item.ThumbnailURL), item.ThumbnailWidth, item.ThumbnailHeight,
+			html.EscapeString(item.FileName))
+		fmt.Fprintf(&b, "<figcaption><a href=\"%s\">No.%d</a> %dx%d</figcaption></figure>\n",
+			html.EscapeString(item.PostURL), item.Post, item.Width, item.Height)
+	}
+
+	b.WriteString("</div>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const galleryHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+.gallery { display: flex; flex-wrap: wrap; gap: 8px; }
+.gallery figure { margin: 0; text-align: center; font: 12px sans-serif; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p>%d files.</p>
+<div class="gallery">
+`
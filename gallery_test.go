@@ -0,0 +1,65 @@
+package fourchan
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildGallery(t *testing.T) {
+	thread := &Thread{Board: "wg", Posts: []Post{
+		{Subject: "Mountains", Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".jpg",
+			FileWidth: 1920, FileHeight: 1080, ThumbnailWidth: 250, ThumbnailHeight: 140}},
+		{Meta: Meta{PostNumber: 2}},
+		{Meta: Meta{PostNumber: 3, HasFile: true, FileDeleted: true, RenamedFileName: 101, FileExt: ".png"}},
+		{Meta: Meta{PostNumber: 4, HasFile: true, Spoiler: true, RenamedFileName: 102, FileExt: ".png"}},
+	}}
+	thread.Posts[0].OrigFileName = "alps"
+
+	gallery := BuildGallery([]*Thread{thread}, nil)
+	if gallery.Title != "Mountains" || len(gallery.Items) != 2 {
+		t.Fatalf("bad gallery %+v", gallery)
+	}
+	item := gallery.Items[0]
+	if item.URL != "https://i.4cdn.org/wg/100.jpg" || item.ThumbnailURL != "https://i.4cdn.org/wg/100s.jpg" ||
+		item.PostURL != "https://boards.4chan.org/wg/thread/1" || item.Thread != 1 {
+		t.Fatalf("bad item %+v", item)
+	}
+
+	gallery = BuildGallery([]*Thread{thread}, &GalleryOptions{Title: "Walls", MediaPath: "images", SkipSpoilers: true})
+	if gallery.Title != "Walls" || len(gallery.Items) != 1 {
+		t.Fatalf("bad gallery %+v", gallery)
+	}
+	if gallery.Items[0].URL != "images/100.jpg" || gallery.Items[0].ThumbnailURL != "images/100s.jpg" {
+		t.Fatalf("bad local item %+v", gallery.Items[0])
+	}
+
+	var manifest bytes.Buffer
+	if err := gallery.WriteJSON(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Gallery{}
+	if err := json.Unmarshal(manifest.Bytes(), decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Title != "Walls" || len(decoded.Items) != 1 || decoded.Items[0].Width != 1920 {
+		t.Fatalf("bad manifest:\n%s", manifest.String())
+	}
+
+	var page bytes.Buffer
+	if err := gallery.WriteHTML(&page); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(page.String(), `<a href="images/100.jpg"><img src="images/100s.jpg" width="250" height="140" alt="alps.jpg"`) {
+		t.Fatalf("bad html:\n%s", page.String())
+	}
+}
+
+func TestGalleryMediaRoute(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".png"}}}}
+	gallery := BuildGallery([]*Thread{thread}, &GalleryOptions{MediaRoute: "/media"})
+	if item := gallery.Items[0]; item.URL != "/media/g/100.png" || item.ThumbnailURL != "/media/g/100s.jpg" {
+		t.Fatalf("bad item %+v", item)
+	}
+}
@@ -0,0 +1,133 @@
+package fourchan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// How often one thread quotes another.
+type ThreadLink struct {
+	From ThreadRef `json:"from"`
+	To   ThreadRef `json:"to"`
+	// How many quotes of the other thread's posts there are.
+	Count int `json:"count"`
+}
+
+// Which threads quote which, for following a discussion across a board.
+type ThreadGraph struct {
+	// Every thread given and every thread they quote, ordered by board and number.
+	Threads []ThreadRef `json:"threads"`
+	// Ordered by From, then To.
+	Links []ThreadLink `json:"links"`
+}
+
+// Build the graph from the quote links in the threads alone, without loading anything.
+// Dead links don't say which thread they were in, so they are left out, see Client.ThreadGraph.
+func BuildThreadGraph(threads []*Thread) *ThreadGraph {
+	g := newGraphBuilder()
+	for _, thread := range threads {
+		from := thread.Ref()
+		g.thread(from)
+		for i := range thread.Posts {
+			walkNodes(thread.Posts[i].CommentNodes(), func(n *CommentNode) bool {
+				if n.Kind == NodeQuoteLink && n.Thread != 0 {
+					board := n.Board
+					if board == "" {
+						board = thread.Board
+					}
+					g.link(from, ThreadRef{board, n.Thread})
+				}
+				return true
+			})
+		}
+	}
+	return g.graph()
+}
+
+// Build the graph of the threads by resolving their quotes with ResolveQuotes, so quotes of
+// deleted posts count too if the client's archives know where they were.
+func (c *Client) ThreadGraph(ctx context.Context, threads []*Thread) (*ThreadGraph, error) {
+	g := newGraphBuilder()
+	for _, thread := range threads {
+		from := thread.Ref()
+		g.thread(from)
+		quotes, err := c.ResolveQuotes(ctx, thread)
+		if err != nil {
+			return nil, err
+		}
+		for _, locations := range quotes {
+			for _, location := range locations {
+				g.link(from, location.Ref())
+			}
+		}
+	}
+	return g.graph(), nil
+}
+
+// Collects threads and links, ignoring threads quoting themselves.
+type graphBuilder struct {
+	threads map[ThreadRef]bool
+	links   map[[2]ThreadRef]int
+}
+
+func newGraphBuilder() *graphBuilder {
+	return &graphBuilder{threads: map[ThreadRef]bool{}, links: map[[2]ThreadRef]int{}}
+}
+
+func (g *graphBuilder) thread(ref ThreadRef) {
+	g.threads[ref] = true
+}
+
+func (g *graphBuilder) link(from, to ThreadRef) {
+	if from == to {
+		return
+	}
+	g.threads[to] = true
+	g.links[[2]ThreadRef{from, to}]++
+}
+
+func (g *graphBuilder) graph() *ThreadGraph {
+	graph := &ThreadGraph{Threads: []ThreadRef{}, Links: []ThreadLink{}}
+	for ref := range g.threads {
+		graph.Threads = append(graph.Threads, ref)
+	}
+	for pair, count := range g.links {
+		graph.Links = append(graph.Links, ThreadLink{pair[0], pair[1], count})
+	}
+	sort.Slice(graph.Threads, func(i, j int) bool { return refLess(graph.Threads[i], graph.Threads[j]) })
+	sort.Slice(graph.Links, func(i, j int) bool {
+		a, b := graph.Links[i], graph.Links[j]
+		if a.From != b.From {
+			return refLess(a.From, b.From)
+		}
+		return refLess(a.To, b.To)
+	})
+	return graph
+}
+
+// Write the graph in Graphviz's DOT language, with the quote counts as edge weights.
+func (g *ThreadGraph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph threads {"); err != nil {
+		return err
+	}
+	for _, ref := range g.Threads {
+		if _, err := fmt.Fprintf(w, "\t%q;\n", ref.String()); err != nil {
+			return err
+		}
+	}
+	for _, link := range g.Links {
+		if _, err := fmt.Fprintf(w, "\t%q -> %q [weight=%d, label=%d];\n", link.From.String(), link.To.String(), link.Count, link.Count); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// Write the graph as JSON.
+func (g *ThreadGraph) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g)
+}
@@ -0,0 +1,68 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestBuildThreadGraph(t *testing.T) {
+	threads := []*Thread{
+		{Board: "g", Posts: []Post{
+			{Meta: Meta{PostNumber: 1}},
+			{Meta: Meta{PostNumber: 2}, Comment: `<a href="#p1" class="quotelink">&gt;&gt;1</a> <a href="/g/thread/5#p6" class="quotelink">&gt;&gt;6</a>`},
+			{Meta: Meta{PostNumber: 3}, Comment: `<a href="/g/thread/5#p7" class="quotelink">&gt;&gt;7</a> <a href="/v/thread/9#p9" class="quotelink">&gt;&gt;&gt;/v/9</a>`},
+			{Meta: Meta{PostNumber: 4}, Comment: `<a href="/g/thread/1#p2" class="quotelink">&gt;&gt;2</a> <span class="deadlink">&gt;&gt;8</span>`},
+		}},
+		{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 5}, Comment: `<a href="/g/thread/1#p1" class="quotelink">&gt;&gt;1</a>`}}},
+	}
+
+	graph := BuildThreadGraph(threads)
+	want := &ThreadGraph{
+		Threads: []ThreadRef{{"g", 1}, {"g", 5}, {"v", 9}},
+		Links:   []ThreadLink{{ThreadRef{"g", 1}, ThreadRef{"g", 5}, 2}, {ThreadRef{"g", 1}, ThreadRef{"v", 9}, 1}, {ThreadRef{"g", 5}, ThreadRef{"g", 1}, 1}},
+	}
+	if !reflect.DeepEqual(graph, want) {
+		t.Fatalf("got  %+v\nwant %+v", graph, want)
+	}
+
+	var b bytes.Buffer
+	if err := graph.WriteDOT(&b); err != nil {
+		t.Fatal(err)
+	}
+	dot := "digraph threads {\n\t\"/g/1\";\n\t\"/g/5\";\n\t\"/v/9\";\n" +
+		"\t\"/g/1\" -> \"/g/5\" [weight=2, label=2];\n\t\"/g/1\" -> \"/v/9\" [weight=1, label=1];\n\t\"/g/5\" -> \"/g/1\" [weight=1, label=1];\n}\n"
+	if b.String() != dot {
+		t.Fatalf("bad DOT\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := graph.WriteJSON(&b); err != nil {
+		t.Fatal(err)
+	}
+	var decoded ThreadGraph
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || !reflect.DeepEqual(&decoded, want) {
+		t.Fatalf("bad JSON %s %v", b.String(), err)
+	}
+}
+
+func TestClientThreadGraph(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	archive := fakeSearcher{fakeArchive{"g/3": {Posts: []Post{{Meta: Meta{PostNumber: 3}}, {Meta: Meta{PostNumber: 4}}}}}}
+	client := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithHTTPClient(server.Client()), WithArchiveFallback(archive))
+	thread := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1}, Comment: `<span class="deadlink">&gt;&gt;4</span>`}}}
+
+	graph, err := client.ThreadGraph(context.Background(), []*Thread{thread})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Links) != 1 || graph.Links[0] != (ThreadLink{ThreadRef{"g", 1}, ThreadRef{"g", 3}, 1}) {
+		t.Fatalf("bad graph %+v", graph)
+	}
+}
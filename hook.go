@@ -0,0 +1,116 @@
+package fourchan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Returned by a post hook to leave the post out of the thread.
+var SkipPost = errors.New("skip this post")
+
+// Call hook for every post of every thread the client loads, as the post is decoded.
+// The hook can fill in or normalize fields, or return SkipPost to drop the post, which is never
+// kept around. Any other error fails the load with it. Skipping the OP leaves a thread that most of
+// the package can't make sense of. Posts of threads from archives are passed to the hook once loaded.
+func WithPostHook(hook func(*Post) error) Option {
+	return func(c *Client) {
+		c.postHook = hook
+	}
+}
+
+// A thread being decoded with a post hook.
+type hookedThread struct {
+	thread *Thread
+	hook   func(*Post) error
+}
+
+// Custom unmarshaler to run the hook on each post as it's decoded, rather than decoding every post first.
+func (h *hookedThread) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "posts" {
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err = expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			var post Post
+			if err = dec.Decode(&post); err != nil {
+				return err
+			}
+			if err = h.hook(&post); err == SkipPost {
+				continue
+			} else if err != nil {
+				return postHookError{err}
+			}
+			h.thread.Posts = append(h.thread.Posts, post)
+		}
+		if err = expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// A post hook's error, passed through decoding to be returned as is rather than as a DecodeError.
+type postHookError struct {
+	err error
+}
+
+func (e postHookError) Error() string {
+	return e.err.Error()
+}
+
+// Read the next token, which has to be the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("Expected %v in thread JSON, got %v", delim, token)
+	}
+	return nil
+}
+
+// Run the hook over posts that were decoded some other way, such as an archive's.
+func (c *Client) hookPosts(thread *Thread) error {
+	if c.postHook == nil {
+		return nil
+	}
+	kept := thread.Posts[:0]
+	for i := range thread.Posts {
+		err := c.postHook(&thread.Posts[i])
+		if err == SkipPost {
+			continue
+		} else if err != nil {
+			return err
+		}
+		kept = append(kept, thread.Posts[i])
+	}
+	thread.Posts = kept
+	return nil
+}
+
+// What to decode a thread's JSON into, so it goes through the client's post hook if it has one.
+func (c *Client) threadTarget(thread *Thread) interface{} {
+	if c.postHook == nil {
+		return thread
+	}
+	return &hookedThread{thread, c.postHook}
+}
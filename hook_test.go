@@ -0,0 +1,67 @@
+package fourchan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/thread/1.json":
+			w.Write([]byte(`{"posts": [{"no": 1, "com": "op"}, {"no": 2, "com": "spam"}, {"no": 3, "com": "reply"}], "extra": {"ignored": [1]}}`))
+		case "/g/thread/2.json":
+			w.Write([]byte(`{"posts": [{"no": 2, "com": "bad"}]}`))
+		case "/g/thread/3.json":
+			w.Write([]byte(`["not", "a", "thread"]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	failed := errors.New("bad post")
+	hook := func(p *Post) error {
+		switch p.Comment {
+		case "spam":
+			return SkipPost
+		case "bad":
+			return failed
+		}
+		p.Comment = strings.ToUpper(p.Comment)
+		return nil
+	}
+	archive := fakeArchive{"g/4": {Posts: []Post{{Meta: Meta{PostNumber: 4}, Comment: "archived"}, {Comment: "spam"}}}}
+	client := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithHTTPClient(server.Client()), WithPostHook(hook), WithArchiveFallback(archive))
+	ctx := context.Background()
+
+	thread, err := client.LoadThread(ctx, "g", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(thread.Posts) != 2 || thread.Posts[0].Comment != "OP" || thread.Posts[1].PostNumber != 3 || thread.Posts[1].Comment != "REPLY" {
+		t.Fatalf("bad thread %+v", thread.Posts)
+	}
+
+	if _, err = client.LoadThread(ctx, "g", 2); err != failed {
+		t.Fatalf("expected the hook's error, got %v", err)
+	}
+	w := &ThreadWatcher{Board: "g", ID: 2, Interval: time.Hour, Client: client}
+	watchCtx, cancel := context.WithCancel(ctx)
+	if event := nextEvent(t, w.Watch(watchCtx)); event.Kind != WatchError || event.Err != failed {
+		t.Fatalf("expected the hook's error from the watcher, got %+v", event)
+	}
+	cancel()
+	if _, err = client.LoadThread(ctx, "g", 3); err == nil {
+		t.Fatal("decoded an array as a thread")
+	}
+
+	if thread, err = client.LoadThread(ctx, "g", 4); err != nil || len(thread.Posts) != 1 || thread.Posts[0].Comment != "ARCHIVED" {
+		t.Fatalf("bad archived thread %+v %v", thread, err)
+	}
+}
@@ -0,0 +1,52 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// A post along with the context of the thread it came from.
+// This is the record written for each line by WriteJSONL.
+type JSONLPost struct {
+	// The board the thread is on.
+	Board string `json:"board"`
+	// The post number of the OP.
+	ThreadID uint64 `json:"thread"`
+	// The subject of the OP.
+	ThreadSubject string `json:"thread_subject"`
+	// unix time the thread was last modified
+	ThreadLastModified uint64 `json:"thread_last_modified,omitempty"`
+	// unix time the thread was archived, 0 if it is still live
+	ThreadArchivedOn uint64 `json:"thread_archived_on,omitempty"`
+
+	// The post itself.
+	Post *Post `json:"post"`
+}
+
+// Flatten threads into JSON Lines, one post per line.
+func WriteJSONL(w io.Writer, threads ...*Thread) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	for _, thread := range threads {
+		if len(thread.Posts) == 0 {
+			continue
+		}
+		op := &thread.Posts[0]
+		for i := range thread.Posts {
+			record := JSONLPost{
+				Board:              thread.Board,
+				ThreadID:           op.PostNumber,
+				ThreadSubject:      op.Subject,
+				ThreadLastModified: op.LastModified,
+				ThreadArchivedOn:   op.ArchivedOn,
+				Post:               &thread.Posts[i],
+			}
+			if err := enc.Encode(&record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package fourchan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteJSONL(t *testing.T) {
+	thread := &Thread{
+		Board: "g",
+		Posts: []Post{
+			{Subject: "/dpt/", Meta: Meta{PostNumber: 10, ArchivedOn: 1500, Sticky: true}},
+			{Comment: "first", Meta: Meta{PostNumber: 11, ReplyTo: 10}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, thread, &Thread{Board: "empty"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []JSONLPost
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record JSONLPost
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("%d records != 2", len(records))
+	}
+	for _, record := range records {
+		if record.Board != "g" || record.ThreadID != 10 || record.ThreadSubject != "/dpt/" || record.ThreadArchivedOn != 1500 {
+			t.Fatalf("bad context: %+v", record)
+		}
+	}
+	if !records[0].Post.Sticky || records[1].Post.Comment != "first" {
+		t.Fatal("post fields did not round trip")
+	}
+}
@@ -0,0 +1,82 @@
+package fourchan
+
+import (
+	"context"
+)
+
+// A file posted on a board, without the file itself.
+type ManifestEntry struct {
+	Board  string   `json:"board"`
+	Thread ThreadID `json:"thread"`
+	Post   PostID   `json:"post"`
+	// The base64 MD5 of the file, as the API reports it.
+	MD5 string `json:"md5"`
+	// The name the file was posted with, see Post.FileName.
+	FileName string `json:"filename"`
+	// Size of the file in bytes, UnknownSize when the API didn't say.
+	Size int `json:"size"`
+}
+
+// The files in a thread, in post order, leaving out deleted ones.
+func (t *Thread) Manifest() []ManifestEntry {
+	var entries []ManifestEntry
+	if len(t.Posts) == 0 {
+		return entries
+	}
+	op := t.Posts[0].PostNumber
+	for i := range t.Posts {
+		post := &t.Posts[i]
+		if !post.HasFile || post.FileDeleted {
+			continue
+		}
+		entries = append(entries, ManifestEntry{
+			Board:    t.Board,
+			Thread:   op,
+			Post:     post.PostNumber,
+			MD5:      post.FileMD5,
+			FileName: post.FileName(),
+			Size:     post.FileSize,
+		})
+	}
+	return entries
+}
+
+// Hand every file on a board to emit, see Client.BoardManifest.
+func BoardManifest(board string, emit func(ManifestEntry) error) error {
+	return defaultClient().BoardManifest(context.Background(), board, emit)
+}
+
+// Hand every file on a board to emit, thread by thread in catalog order, without fetching any of them.
+// This lets collectors decide which files they want, e.g. by MD5, before downloading anything.
+// Threads that disappear before they are fetched are skipped, and threads that fail to load don't stop
+// the rest, they are listed in the BatchError returned at the end. An error from emit stops the walk
+// and is returned as is, as is the context's error once it is done.
+func (c *Client) BoardManifest(ctx context.Context, board string, emit func(ManifestEntry) error) error {
+	catalog, err := c.LoadCatalog(ctx, board)
+	if err != nil {
+		return err
+	}
+
+	var failures batchErrors
+	for _, preview := range catalog.Threads() {
+		id := preview.Posts[0].PostNumber
+		thread, err := c.LoadThread(ctx, board, id)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, gone := err.(ThreadNotFoundError); gone {
+			continue
+		}
+		failures.add(ThreadRef{board, id}.String(), err)
+		if err != nil {
+			continue
+		}
+		for _, entry := range thread.Manifest() {
+			if err = emit(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	return failures.err()
+}
@@ -0,0 +1,56 @@
+package fourchan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBoardManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/catalog.json":
+			fmt.Fprint(w, `[{"page": 1, "threads": [{"no": 10}, {"no": 11}, {"no": 12}]}, {"page": 2, "threads": [{"no": 13}]}]`)
+		case "/g/thread/10.json":
+			fmt.Fprint(w, `{"posts": [
+				{"no": 10, "tim": 100, "ext": ".jpg", "filename": "desk", "md5": "abc==", "fsize": 2000},
+				{"no": 14},
+				{"no": 15, "tim": 101, "ext": ".png", "filename": "gone", "filedeleted": 1}
+			]}`)
+		case "/g/thread/12.json":
+			http.Error(w, "backend down", http.StatusBadGateway)
+		case "/g/thread/13.json":
+			fmt.Fprint(w, `{"posts": [{"no": 13, "tim": 102, "ext": ".webm", "filename": "clip", "md5": "def==", "fsize": 5000}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	var entries []ManifestEntry
+	err := BoardManifest("g", func(entry ManifestEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	batch, ok := err.(BatchError)
+	if !ok || batch.Total != 3 || len(batch.Failures) != 1 || batch.Failures[0].Item != "/g/12" {
+		t.Fatalf("expected the failed thread to be reported, got %v", err)
+	}
+	want := []ManifestEntry{
+		{Board: "g", Thread: 10, Post: 10, MD5: "abc==", FileName: "desk.jpg", Size: 2000},
+		{Board: "g", Thread: 13, Post: 13, MD5: "def==", FileName: "clip.webm", Size: 5000},
+	}
+	if len(entries) != len(want) || entries[0] != want[0] || entries[1] != want[1] {
+		t.Fatalf("bad manifest %+v", entries)
+	}
+
+	full := errors.New("disk full")
+	if err = BoardManifest("g", func(ManifestEntry) error { return full }); err != full {
+		t.Fatalf("emit error not returned: %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package fourchan
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The kinds of node in a parsed comment.
+type NodeKind int
+
+const (
+	// Plain text, in Text.
+	NodeText NodeKind = iota
+	// A >>123 link to another post, or >>>/g/ to another board.
+	NodeQuoteLink
+	// A >greentext line, its contents are in Children.
+	NodeGreentext
+	// A line break.
+	NodeLineBreak
+	// A [spoiler], its contents are in Children.
+	NodeSpoiler
+	// A [code] block, its text including newlines is in Text.
+	NodeCode
+	// A quote of a post that has since been deleted.
+	NodeDeadLink
+)
+
+// Name the kind of node.
+func (k NodeKind) String() string {
+	switch k {
+	case NodeText:
+		return "text"
+	case NodeQuoteLink:
+		return "quote link"
+	case NodeGreentext:
+		return "greentext"
+	case NodeLineBreak:
+		return "line break"
+	case NodeSpoiler:
+		return "spoiler"
+	case NodeCode:
+		return "code"
+	case NodeDeadLink:
+		return "dead link"
+	}
+	return fmt.Sprintf("NodeKind(%d)", int(k))
+}
+
+// One piece of a parsed comment.
+type CommentNode struct {
+	Kind NodeKind
+	// The decoded text of text, code, quote link and dead link nodes.
+	Text string
+	// The contents of greentext and spoiler nodes.
+	Children []CommentNode
+
+	// Where a quote or dead link points. Board is empty and Thread is 0 for posts in the same thread,
+	// and Post is 0 for links to a whole board.
+	Board  string
+	Thread ThreadID
+	Post   PostID
+	// The linked post is gone. Always set for dead links, Thread.MarkDeadLinks sets it for quote links.
+	Dead bool
+}
+
+// Parse the comment HTML into nodes.
+// Formatting the parser doesn't know (bold, colored text, ...) is kept as its text.
+func ParseComment(comment string) []CommentNode {
+	p := &markupParser{stack: []markupFrame{{node: &CommentNode{}}}}
+	for len(comment) > 0 {
+		start := strings.IndexByte(comment, '<')
+		if start < 0 {
+			p.text(comment)
+			break
+		}
+		p.text(comment[:start])
+		comment = comment[start:]
+
+		end := strings.IndexByte(comment, '>')
+		if end < 0 {
+			// Not a tag, just a stray bracket.
+			p.text(comment)
+			break
+		}
+		p.tag(comment[1:end])
+		comment = comment[end+1:]
+	}
+
+	for len(p.stack) > 1 {
+		p.pop()
+	}
+	return p.stack[0].node.Children
+}
+
+// The comment parsed into nodes, see ParseComment.
+func (p *Post) CommentNodes() []CommentNode {
+	return ParseComment(p.Comment)
+}
+
+// The plain text of the node and everything in it.
+func (n *CommentNode) PlainText() string {
+	switch n.Kind {
+	case NodeLineBreak:
+		return "\n"
+	case NodeGreentext, NodeSpoiler:
+		return nodesText(n.Children)
+	}
+	return n.Text
+}
+
+func nodesText(nodes []CommentNode) string {
+	var b strings.Builder
+	for i := range nodes {
+		b.WriteString(nodes[i].PlainText())
+	}
+	return b.String()
+}
+
+// The plain text of each spoiler in the comment, in order.
+// Spoilers inside spoilers are part of the outer one.
+func (p *Post) Spoilers() []string {
+	var spoilers []string
+	walkNodes(p.CommentNodes(), func(n *CommentNode) bool {
+		if n.Kind == NodeSpoiler {
+			spoilers = append(spoilers, n.PlainText())
+			return false
+		}
+		return true
+	})
+	return spoilers
+}
+
+// Call visit for every node, depth first. Children are skipped when visit returns false.
+func walkNodes(nodes []CommentNode, visit func(n *CommentNode) bool) {
+	for i := range nodes {
+		if visit(&nodes[i]) {
+			walkNodes(nodes[i].Children, visit)
+		}
+	}
+}
+
+// Mark the quote links in nodes that point into this thread at posts it doesn't have, as 4chan does with "(Dead)".
+// Links to other threads are left alone, there's no telling whether those posts exist without loading them.
+// Archives, which only know post numbers, link cross thread quotes as if they were in the thread, so those are marked too.
+func (t *Thread) MarkDeadLinks(nodes []CommentNode) {
+	inThread := map[uint64]bool{}
+	var op uint64
+	for i := range t.Posts {
+		inThread[t.Posts[i].PostNumber] = true
+	}
+	if len(t.Posts) > 0 {
+		op = t.Posts[0].PostNumber
+	}
+
+	walkNodes(nodes, func(n *CommentNode) bool {
+		if n.Kind != NodeQuoteLink || n.Post == 0 {
+			return true
+		}
+		sameThread := (n.Board == "" || n.Board == t.Board) && (n.Thread == 0 || n.Thread == op)
+		if sameThread && !inThread[n.Post] {
+			n.Dead = true
+		}
+		return true
+	})
+}
+
+// The dead links in every post of the thread, each linked post listed once, in the order they're first quoted.
+// These are the posts an archiver would have to look for elsewhere.
+func (t *Thread) DeadLinks() []CommentNode {
+	var dead []CommentNode
+	seen := map[string]bool{}
+	for i := range t.Posts {
+		nodes := t.Posts[i].CommentNodes()
+		t.MarkDeadLinks(nodes)
+		walkNodes(nodes, func(n *CommentNode) bool {
+			board := n.Board
+			if board == "" {
+				board = t.Board
+			}
+			key := fmt.Sprintf("%s/%d", board, n.Post)
+			if n.Dead && n.Post != 0 && !seen[key] {
+				seen[key] = true
+				dead = append(dead, *n)
+			}
+			return true
+		})
+	}
+	return dead
+}
+
+// An open tag, and the node it produces if it is one the parser knows.
+type markupFrame struct {
+	tag  string
+	node *CommentNode
+}
+
+type markupParser struct {
+	stack []markupFrame
+}
+
+// The innermost node being built.
+func (p *markupParser) current() *CommentNode {
+	for i := len(p.stack) - 1; i >= 0; i-- {
+		if p.stack[i].node != nil {
+			return p.stack[i].node
+		}
+	}
+	return nil
+}
+
+// Add some still escaped text.
+func (p *markupParser) text(raw string) {
+	if raw == "" {
+		return
+	}
+	text := html.UnescapeString(raw)
+	node := p.current()
+	switch node.Kind {
+	case NodeQuoteLink, NodeDeadLink, NodeCode:
+		node.Text += text
+		return
+	}
+	if n := len(node.Children); n > 0 && node.Children[n-1].Kind == NodeText {
+		node.Children[n-1].Text += text
+		return
+	}
+	node.Children = append(node.Children, CommentNode{Kind: NodeText, Text: text})
+}
+
+// Handle the text between a tag's brackets.
+func (p *markupParser) tag(tag string) {
+	name := tagName(tag)
+	switch name {
+	case "br":
+		if node := p.current(); node.Kind == NodeCode {
+			node.Text += "\n"
+		} else {
+			node.Children = append(node.Children, CommentNode{Kind: NodeLineBreak})
+		}
+		return
+	case "wbr", "img", "hr":
+		return
+	}
+
+	if strings.HasPrefix(name, "/") {
+		name = name[1:]
+		for i := len(p.stack) - 1; i > 0; i-- {
+			if p.stack[i].tag == name {
+				for len(p.stack) > i {
+					p.pop()
+				}
+				return
+			}
+		}
+		// Closing a tag that was never opened.
+		return
+	}
+
+	frame := markupFrame{tag: name}
+	class := tagAttr(tag, "class")
+	switch {
+	case name == "a" && class == "quotelink":
+		frame.node = &CommentNode{Kind: NodeQuoteLink}
+		frame.node.Board, frame.node.Thread, frame.node.Post = parseQuoteHref(tagAttr(tag, "href"))
+	case name == "span" && class == "quote":
+		frame.node = &CommentNode{Kind: NodeGreentext}
+	case name == "span" && class == "deadlink":
+		frame.node = &CommentNode{Kind: NodeDeadLink}
+	case name == "s":
+		frame.node = &CommentNode{Kind: NodeSpoiler}
+	case name == "pre":
+		frame.node = &CommentNode{Kind: NodeCode}
+	}
+	p.stack = append(p.stack, frame)
+}
+
+// Close the innermost tag, adding its node to its parent.
+func (p *markupParser) pop() {
+	frame := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	if frame.node == nil {
+		return
+	}
+
+	if frame.node.Kind == NodeDeadLink {
+		frame.node.Board, frame.node.Post = parseDeadLink(frame.node.Text)
+		frame.node.Dead = true
+	}
+	parent := p.current()
+	parent.Children = append(parent.Children, *frame.node)
+}
+
+// Matches an attribute in a tag, quoted or not.
+var tagAttrRegex = regexp.MustCompile(`(?i)\s([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// The decoded value of an attribute given the text between a tag's brackets, empty if it isn't there.
+func tagAttr(tag, attr string) string {
+	for _, match := range tagAttrRegex.FindAllStringSubmatch(tag, -1) {
+		if strings.EqualFold(match[1], attr) {
+			return html.UnescapeString(match[2] + match[3] + match[4])
+		}
+	}
+	return ""
+}
+
+// Matches the links 4chan makes for quotes: #p123, /g/thread/5#p7, //boards.4chan.org/g/thread/5#p7 and /g/.
+var (
+	quotePostRegex   = regexp.MustCompile(`^#p(\d+)$`)
+	quoteThreadRegex = regexp.MustCompile(`/([a-z0-9]+)/thread/(\d+)(?:[^#]*#p(\d+))?`)
+	quoteBoardRegex  = regexp.MustCompile(`/([a-z0-9]+)/`)
+)
+
+// Work out where a quote link's href points.
+// Links to other threads on the same board still name the board, as 4chan doesn't say which board a link is on.
+func parseQuoteHref(href string) (board string, thread, post uint64) {
+	if m := quotePostRegex.FindStringSubmatch(href); m != nil {
+		post, _ = strconv.ParseUint(m[1], 10, 64)
+		return "", 0, post
+	}
+	if m := quoteThreadRegex.FindStringSubmatch(href); m != nil {
+		thread, _ = strconv.ParseUint(m[2], 10, 64)
+		post = thread
+		if m[3] != "" {
+			post, _ = strconv.ParseUint(m[3], 10, 64)
+		}
+		return m[1], thread, post
+	}
+	if m := quoteBoardRegex.FindStringSubmatch(href); m != nil {
+		return m[1], 0, 0
+	}
+	return "", 0, 0
+}
+
+// Matches the text of a dead link, >>123 or >>>/g/123.
+var deadLinkRegex = regexp.MustCompile(`^>>(?:>/([a-z0-9]+)/)?(\d+)`)
+
+// Work out which post a dead link quoted.
+func parseDeadLink(text string) (board string, post uint64) {
+	if m := deadLinkRegex.FindStringSubmatch(text); m != nil {
+		post, _ = strconv.ParseUint(m[2], 10, 64)
+		return m[1], post
+	}
+	return "", 0
+}
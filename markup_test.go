@@ -0,0 +1,87 @@
+package fourchan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseComment(t *testing.T) {
+	comment := `<a href="#p1" class="quotelink">&gt;&gt;1</a><br>` +
+		`<span class="quote">&gt;be me <s>spoiled &amp; <a href="/g/thread/5#p7" class="quotelink">&gt;&gt;7</a></s></span><br>` +
+		`<b>bold</b> text<wbr>more <span class="deadlink">&gt;&gt;&gt;/v/99</span><br>` +
+		`<pre class="prettyprint">int main() {<br>  return 0;<br>}</pre>` +
+		`<a href="//boards.4chan.org/v/" class="quotelink">&gt;&gt;&gt;/v/</a> 1 &lt; 2`
+
+	want := []CommentNode{
+		{Kind: NodeQuoteLink, Text: ">>1", Post: 1},
+		{Kind: NodeLineBreak},
+		{Kind: NodeGreentext, Children: []CommentNode{
+			{Kind: NodeText, Text: ">be me "},
+			{Kind: NodeSpoiler, Children: []CommentNode{
+				{Kind: NodeText, Text: "spoiled & "},
+				{Kind: NodeQuoteLink, Text: ">>7", Board: "g", Thread: 5, Post: 7},
+			}},
+		}},
+		{Kind: NodeLineBreak},
+		{Kind: NodeText, Text: "bold textmore "},
+		{Kind: NodeDeadLink, Text: ">>>/v/99", Board: "v", Post: 99, Dead: true},
+		{Kind: NodeLineBreak},
+		{Kind: NodeCode, Text: "int main() {\n  return 0;\n}"},
+		{Kind: NodeQuoteLink, Text: ">>>/v/", Board: "v"},
+		{Kind: NodeText, Text: " 1 < 2"},
+	}
+
+	got := (&Post{Comment: comment}).CommentNodes()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseCommentMalformed(t *testing.T) {
+	// Unclosed tags are closed at the end, stray closing tags and brackets are ignored.
+	got := ParseComment(`<s>open</b> 1<2`)
+	want := []CommentNode{{Kind: NodeSpoiler, Children: []CommentNode{{Kind: NodeText, Text: "open 1<2"}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+
+	if nodes := ParseComment(""); len(nodes) != 0 {
+		t.Fatalf("nodes for an empty comment: %+v", nodes)
+	}
+}
+
+func TestSpoilers(t *testing.T) {
+	post := &Post{Comment: `<s>Snape</s> kills <span class="quote">&gt;<s>Dumbledore<br>twice</s></span>`}
+	spoilers := post.Spoilers()
+	if len(spoilers) != 2 || spoilers[0] != "Snape" || spoilers[1] != "Dumbledore\ntwice" {
+		t.Fatalf("bad spoilers %q", spoilers)
+	}
+
+	nodes := post.CommentNodes()
+	if text := nodesText(nodes); text != post.PlainText() {
+		t.Fatalf("%q != %q", text, post.PlainText())
+	}
+}
+
+func TestDeadLinks(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 5}},
+		{Meta: Meta{PostNumber: 7}, Comment: `<a href="#p5" class="quotelink">&gt;&gt;5</a> <a href="#p6" class="quotelink">&gt;&gt;6</a>`},
+		{Meta: Meta{PostNumber: 8}, Comment: `<a href="/g/thread/5#p6" class="quotelink">&gt;&gt;6</a> ` +
+			`<a href="/g/thread/9#p10" class="quotelink">&gt;&gt;10</a> <span class="deadlink">&gt;&gt;4</span>`},
+	}}
+
+	nodes := thread.Posts[1].CommentNodes()
+	thread.MarkDeadLinks(nodes)
+	if nodes[0].Dead || !nodes[2].Dead {
+		t.Fatalf("bad marks %+v", nodes)
+	}
+
+	want := []CommentNode{
+		{Kind: NodeQuoteLink, Text: ">>6", Post: 6, Dead: true},
+		{Kind: NodeDeadLink, Text: ">>4", Post: 4, Dead: true},
+	}
+	if got := thread.DeadLinks(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %+v\nwant %+v", got, want)
+	}
+}
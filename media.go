@@ -0,0 +1,159 @@
+package fourchan
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Serves files and thumbnails at /{board}/{name}, e.g. /g/1456789012345.jpg, so web frontends
+// built on the package don't have to link to the media host directly.
+// Mount it under a prefix with http.StripPrefix, e.g. "/media", and link to Post.MediaPath.
+type MediaServer struct {
+	// Where files are kept, as dir/{board}/{name}. Empty to keep nothing locally.
+	Dir string
+	// Fetch files that aren't in Dir from the media host.
+	Fetch bool
+	// Along with Fetch, save fetched files in Dir so they are only fetched once.
+	Save bool
+	// The client to fetch with, defaults to DefaultClient.
+	Client *Client
+	// Makes thumbnails missing from Dir out of the full files there, before trying to fetch them.
+	// The thumbnail subpackage has one, e.g.
+	//	func(w io.Writer, file io.Reader) error { return thumbnail.Generate(w, file, thumbnail.ReplySize) }
+	// With Save the thumbnails made are saved in Dir.
+	Thumbnail func(w io.Writer, file io.Reader) error
+}
+
+// Matches the names the media host serves files and thumbnails under.
+var (
+	mediaBoardRegex = regexp.MustCompile(`^[a-z0-9]+$`)
+	mediaNameRegex  = regexp.MustCompile(`^\d+s?\.[a-z0-9]+$`)
+)
+
+// Where the post's file is served by a MediaServer mounted at prefix, e.g. /media/g/1456789012345.jpg.
+func (p *Post) MediaPath(prefix, board string) string {
+	return path.Join(prefix, board, p.mediaName())
+}
+
+// Where the post's thumbnail is served by a MediaServer mounted at prefix, e.g. /media/g/1456789012345s.jpg.
+func (p *Post) ThumbnailPath(prefix, board string) string {
+	return path.Join(prefix, board, p.thumbnailName())
+}
+
+// Serve the file named by the request path, from Dir or the media host.
+func (s *MediaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 2 || !mediaBoardRegex.MatchString(parts[0]) || !mediaNameRegex.MatchString(parts[1]) {
+		http.NotFound(w, r)
+		return
+	}
+	board, name := parts[0], parts[1]
+
+	local := ""
+	if s.Dir != "" {
+		local = filepath.Join(s.Dir, board, name)
+		if _, err := os.Stat(local); err == nil {
+			http.ServeFile(w, r, local)
+			return
+		}
+	}
+	if local != "" && s.Thumbnail != nil && s.thumbnail(w, r, local) {
+		return
+	}
+	if !s.Fetch {
+		http.NotFound(w, r)
+		return
+	}
+
+	client := s.Client
+	if client == nil {
+		client = defaultClient()
+	}
+	url := fmt.Sprintf("%s/%s/%s", client.mediaURL, board, name)
+
+	if local != "" && s.Save {
+		err := os.MkdirAll(filepath.Dir(local), 0755)
+		if err == nil {
+			err = saveFile(local, false, func(f io.Writer) error {
+				_, err := client.download(r.Context(), url, f)
+				return err
+			})
+		}
+		if err != nil {
+			mediaError(w, err)
+			return
+		}
+		http.ServeFile(w, r, local)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	tw := &trackingWriter{ResponseWriter: w}
+	if _, err := client.download(r.Context(), url, tw); err != nil && !tw.wrote {
+		// Once part of the file is sent all that can be done is to stop.
+		mediaError(w, err)
+	}
+}
+
+// Serve a thumbnail made from the full file next to where it should be.
+// Returns false if there is no full file to make it from, or it can't be made.
+func (s *MediaServer) thumbnail(w http.ResponseWriter, r *http.Request, local string) bool {
+	name := filepath.Base(local)
+	if !strings.HasSuffix(name, "s.jpg") {
+		return false
+	}
+	tim := strings.TrimSuffix(name, "s.jpg")
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(local), tim+".*"))
+	if len(files) == 0 {
+		return false
+	}
+	file, err := os.Open(files[0])
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var thumb bytes.Buffer
+	if err = s.Thumbnail(&thumb, file); err != nil {
+		return false
+	}
+	if s.Save {
+		saveFile(local, false, func(f io.Writer) error {
+			_, err := f.Write(thumb.Bytes())
+			return err
+		})
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Write(thumb.Bytes())
+	return true
+}
+
+// Remembers whether anything was written to the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (w *trackingWriter) Write(p []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Answer with the status a failed fetch deserves.
+func mediaError(w http.ResponseWriter, err error) {
+	if isNotFound(err) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadGateway)
+}
@@ -0,0 +1,118 @@
+package fourchan
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMediaServer(t *testing.T) {
+	fetched := 0
+	host := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		switch r.URL.Path {
+		case "/g/100.png":
+			w.Write([]byte("remote image"))
+		case "/g/100s.jpg":
+			w.Write([]byte("remote thumb"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer host.Close()
+
+	dir, err := ioutil.TempDir("", "fourchan-media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "g"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "g", "101.jpg"), []byte("local image"), 0644)
+
+	client := NewClient(WithMediaURL(host.URL), WithRateLimit(0))
+	media := &MediaServer{Dir: dir, Fetch: true, Save: true, Client: client}
+	server := httptest.NewServer(http.StripPrefix("/media", media))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	post := &Post{Meta: Meta{RenamedFileName: 101, FileExt: ".jpg"}}
+	if code, body := get(post.MediaPath("/media", "g")); code != http.StatusOK || body != "local image" || fetched != 0 {
+		t.Fatalf("bad local file %d %q", code, body)
+	}
+	for i := 0; i < 2; i++ {
+		if code, body := get("/media/g/100.png"); code != http.StatusOK || body != "remote image" || fetched != 1 {
+			t.Fatalf("bad fetched file %d %q after %d fetches", code, body, fetched)
+		}
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "g", "100.png")); string(data) != "remote image" {
+		t.Fatalf("fetched file not saved: %q", data)
+	}
+
+	if code, _ := get("/media/g/102.png"); code != http.StatusNotFound {
+		t.Fatalf("missing file answered %d", code)
+	}
+	if code, _ := get("/media/g/..%2F..%2Fsecret"); code != http.StatusNotFound {
+		t.Fatalf("bad name answered %d", code)
+	}
+
+	// Without a directory files are streamed through.
+	media.Dir = ""
+	if code, body := get("/media/g/100s.jpg"); code != http.StatusOK || body != "remote thumb" {
+		t.Fatalf("bad streamed thumbnail %d %q", code, body)
+	}
+	media.Fetch = false
+	if code, _ := get("/media/g/100s.jpg"); code != http.StatusNotFound {
+		t.Fatalf("fetched without Fetch: %d", code)
+	}
+}
+
+func TestMediaServerThumbnail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fourchan-media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "g"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "g", "100.png"), []byte("full image"), 0644)
+
+	media := &MediaServer{Dir: dir, Save: true, Thumbnail: func(w io.Writer, file io.Reader) error {
+		data, _ := ioutil.ReadAll(file)
+		_, err := fmt.Fprintf(w, "thumbnail of %s", data)
+		return err
+	}}
+	server := httptest.NewServer(media)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/g/100s.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "thumbnail of full image" || resp.Header.Get("Content-Type") != "image/jpeg" {
+		t.Fatalf("bad thumbnail %d %q", resp.StatusCode, body)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(dir, "g", "100s.jpg")); string(data) != "thumbnail of full image" {
+		t.Fatalf("thumbnail not saved: %q", data)
+	}
+
+	// Nothing to make one from.
+	if resp, err = http.Get(server.URL + "/g/101s.jpg"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected not found, got %v %v", resp.Status, err)
+	}
+	resp.Body.Close()
+}
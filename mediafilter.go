@@ -0,0 +1,91 @@
+package fourchan
+
+import (
+	"strings"
+)
+
+// Extensions of still and animated images.
+var imageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true}
+
+// Extensions of videos.
+var videoExtensions = map[string]bool{".webm": true, ".mp4": true}
+
+// Rules deciding which of a post's files are worth fetching.
+// These only look at what the API reports, so they can be checked before any bytes are downloaded.
+// Zero values don't restrict anything.
+type MediaFilter struct {
+	// Allowed extensions including the dot (".webm"), case insensitive.
+	Extensions []string
+	// Smallest and largest file sizes in bytes.
+	MinSize, MaxSize int
+	// Smallest dimensions in pixels.
+	MinWidth, MinHeight int
+	// Only allow jpg, png and gif files.
+	ImagesOnly bool
+	// Only allow webm and mp4 files.
+	VideosOnly bool
+}
+
+// Does the post have a file that passes the filter?
+// A nil filter allows every file.
+func (f *MediaFilter) Match(p *Post) bool {
+	if !p.HasFile || p.FileDeleted {
+		return false
+	}
+	if f == nil {
+		return true
+	}
+
+	ext := strings.ToLower(p.FileExt)
+	if f.ImagesOnly && !imageExtensions[ext] {
+		return false
+	}
+	if f.VideosOnly && !videoExtensions[ext] {
+		return false
+	}
+	if len(f.Extensions) > 0 {
+		allowed := false
+		for _, e := range f.Extensions {
+			if strings.ToLower(e) == ext {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	if f.MinSize > 0 && p.FileSize < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && p.FileSize > f.MaxSize {
+		return false
+	}
+	if p.FileWidth < f.MinWidth || p.FileHeight < f.MinHeight {
+		return false
+	}
+
+	return true
+}
+
+// Media filters configured per board.
+type BoardMediaFilters struct {
+	// Used for boards without their own filter, nil allows everything.
+	Default *MediaFilter
+	// Filters keyed by board, e.g. "wsg".
+	Boards map[string]*MediaFilter
+}
+
+// The filter that applies to a board.
+func (b *BoardMediaFilters) For(board string) *MediaFilter {
+	if f, ok := b.Boards[board]; ok {
+		return f
+	}
+	return b.Default
+}
+
+// Does the post on the given board have a file that passes the board's filter?
+func (b *BoardMediaFilters) Match(board string, p *Post) bool {
+	return b.For(board).Match(p)
+}
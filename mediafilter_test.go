@@ -0,0 +1,52 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestMediaFilter(t *testing.T) {
+	webm := &Post{Meta: Meta{HasFile: true, FileExt: ".webm", FileSize: 3000000, FileWidth: 1280, FileHeight: 720}}
+	jpg := &Post{Meta: Meta{HasFile: true, FileExt: ".JPG", FileSize: 50000, FileWidth: 320, FileHeight: 240}}
+	deleted := &Post{Meta: Meta{HasFile: true, FileDeleted: true, FileExt: ".jpg"}}
+	text := &Post{}
+
+	tests := []struct {
+		filter *MediaFilter
+		post   *Post
+		match  bool
+	}{
+		{nil, webm, true},
+		{nil, deleted, false},
+		{nil, text, false},
+		{&MediaFilter{ImagesOnly: true}, webm, false},
+		{&MediaFilter{ImagesOnly: true}, jpg, true},
+		{&MediaFilter{VideosOnly: true}, webm, true},
+		{&MediaFilter{Extensions: []string{".jpg"}}, jpg, true},
+		{&MediaFilter{Extensions: []string{".png", ".gif"}}, jpg, false},
+		{&MediaFilter{MaxSize: 1000000}, webm, false},
+		{&MediaFilter{MinSize: 100000}, jpg, false},
+		{&MediaFilter{MinWidth: 1920}, webm, false},
+		{&MediaFilter{MinWidth: 1280, MinHeight: 720}, webm, true},
+	}
+
+	for i, test := range tests {
+		if match := test.filter.Match(test.post); match != test.match {
+			t.Fatalf("%d: %v != %v", i, match, test.match)
+		}
+	}
+}
+
+func TestBoardMediaFilters(t *testing.T) {
+	filters := &BoardMediaFilters{
+		Default: &MediaFilter{ImagesOnly: true},
+		Boards:  map[string]*MediaFilter{"wsg": {VideosOnly: true}},
+	}
+	webm := &Post{Meta: Meta{HasFile: true, FileExt: ".webm"}}
+
+	if !filters.Match("wsg", webm) {
+		t.Fatal("webm rejected on wsg")
+	}
+	if filters.Match("g", webm) {
+		t.Fatal("webm allowed on g")
+	}
+}
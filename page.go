@@ -0,0 +1,93 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// One index page of a board as served from /{board}/{page}.json.
+// Threads only contain their OP and latest replies, see Thread.IsPreview.
+type Page struct {
+	// The threads on the page in index order.
+	Threads []*Thread `json:"threads"`
+	// The board the page is from.
+	Board string
+	// The page number, starting at 1.
+	Number int
+	// When the page was fetched.
+	FetchedAt time.Time
+}
+
+// Load an index page of a board, starting at 1.
+func LoadBoardPage(board string, page int) (*Page, error) {
+	return defaultClient().LoadBoardPage(context.Background(), board, page)
+}
+
+// Load an index page of a board, starting at 1.
+func (c *Client) LoadBoardPage(ctx context.Context, board string, page int) (*Page, error) {
+	p := &Page{}
+	_, err := c.getJSON(ctx, fmt.Sprintf("%s/%s/%d.json", c.apiURL, board, page), p)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Board = board
+	p.Number = page
+	p.FetchedAt = time.Now()
+	quirks := QuirksFor(board)
+	for _, thread := range p.Threads {
+		thread.Board = board
+		quirks.fixPosts(thread.Posts)
+		thread.FetchedAt = p.FetchedAt
+		// The page's Last-Modified is that of the whole board, so each thread goes by its OP's instead.
+		if len(thread.Posts) > 0 && thread.Posts[0].LastModified != 0 {
+			thread.ModifiedAt = time.Unix(int64(thread.Posts[0].LastModified), 0)
+		}
+	}
+
+	return p, nil
+}
+
+// Is this only a preview of the thread, as found on index pages?
+// Previews have replies left out, which the OP reports as omitted.
+func (t *Thread) IsPreview() bool {
+	return len(t.Posts) > 0 && (t.Posts[0].OmittedPosts > 0 || t.Posts[0].OmittedImages > 0)
+}
+
+// Replace a preview with the full thread.
+// Does nothing if the thread is already complete or immutable.
+// This changes t in place, see Thread for when that matters.
+func (t *Thread) Expand() error {
+	return defaultClient().Expand(context.Background(), t)
+}
+
+// Replace a preview with the full thread.
+// Does nothing if the thread is already complete or immutable.
+// This changes t in place, use Expanded for threads other goroutines may be reading.
+func (c *Client) Expand(ctx context.Context, t *Thread) error {
+	full, err := c.Expanded(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	*t = *full
+	return nil
+}
+
+// The full thread for a preview, or t itself if it is already complete or immutable.
+// t is left untouched.
+func (c *Client) Expanded(ctx context.Context, t *Thread) (*Thread, error) {
+	if !t.IsPreview() || t.IsImmutable() {
+		return t, nil
+	}
+	return c.LoadThread(ctx, t.Board, t.Posts[0].PostNumber)
+}
+
+// Every post in the thread, loading the full thread first if this is a preview.
+func (t *Thread) AllPosts() ([]Post, error) {
+	if err := t.Expand(); err != nil {
+		return nil, err
+	}
+	return t.Posts, nil
+}
@@ -0,0 +1,68 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadBoardPageExpands(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/1.json":
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+			fmt.Fprint(w, `{"threads": [
+				{"posts": [{"no": 1, "replies": 3, "omitted_posts": 2, "last_modified": 1500000000}, {"no": 4}]},
+				{"posts": [{"no": 5, "replies": 1}, {"no": 6}]}
+			]}`)
+		case "/g/thread/1.json":
+			fmt.Fprint(w, `{"posts": [{"no": 1, "replies": 3}, {"no": 2}, {"no": 3}, {"no": 4}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	page, err := LoadBoardPage("g", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Threads) != 2 || page.Number != 1 || page.Threads[0].Board != "g" {
+		t.Fatalf("bad page %+v", page)
+	}
+
+	preview, complete := page.Threads[0], page.Threads[1]
+	if preview.ModifiedAt.Unix() != 1500000000 || !complete.ModifiedAt.IsZero() {
+		t.Fatalf("threads stamped with the page's Last-Modified: %v %v", preview.ModifiedAt, complete.ModifiedAt)
+	}
+	if !preview.IsPreview() || complete.IsPreview() {
+		t.Fatal("bad preview detection")
+	}
+
+	full, err := DefaultClient.Expanded(context.Background(), preview)
+	if err != nil || len(full.Posts) != 4 || !preview.IsPreview() {
+		t.Fatalf("Expanded changed the preview or failed: %v", err)
+	}
+	if same, _ := DefaultClient.Expanded(context.Background(), complete); same != complete {
+		t.Fatal("complete thread reloaded")
+	}
+
+	posts, err := preview.AllPosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 4 || preview.IsPreview() || preview.Board != "g" {
+		t.Fatalf("preview not expanded: %+v", preview)
+	}
+
+	posts, err = complete.AllPosts()
+	if err != nil || len(posts) != 2 {
+		t.Fatalf("complete thread changed: %v %v", posts, err)
+	}
+}
@@ -0,0 +1,137 @@
+package fourchan
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+// Something an event plugin asked for.
+type PluginAction struct {
+	// What to do, e.g. "download", "tag" or "notify". What actions there are is up to the handler.
+	Action string   `json:"action"`
+	Board  string   `json:"board,omitempty"`
+	Thread ThreadID `json:"thread,omitempty"`
+	Post   PostID   `json:"post,omitempty"`
+	// Whatever else the plugin sent along, e.g. the tag to add.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// A watch event as a plugin reads it.
+// The snapshot is left out, plugins that need the whole thread can load it.
+type pluginEvent struct {
+	Kind   string   `json:"kind"`
+	Board  string   `json:"board"`
+	Thread ThreadID `json:"thread"`
+	Posts  []Post   `json:"posts,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// The longest line of JSON a plugin can write.
+const maxPluginLine = 1 << 20
+
+// Run an external program as a plugin, so programs in any language can act on watch events.
+// Each event is written to the program's stdin as one line of JSON, with its kind, board, thread, posts and
+// error, but not its snapshot. Each line the program writes to stdout has to be a PluginAction as JSON,
+// which is passed to handle. Once events is closed the program's stdin is closed, and RunPlugin returns
+// when the program exits. An error from handle, a line that isn't an action or ctx being done kill the
+// program and are returned, otherwise the error is the program's own, from exec.Cmd.Wait.
+// cmd must not have been started, and its stdin and stdout must not be set.
+func RunPlugin(ctx context.Context, cmd *exec.Cmd, events <-chan WatchEvent, handle func(PluginAction) error) error {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cmd.Process.Kill()
+		case <-stop:
+		}
+	}()
+
+	var readErr error
+	reading := make(chan struct{})
+	go func() {
+		defer close(reading)
+		if readErr = readPluginActions(stdout, handle); readErr != nil {
+			// Nothing reads what the program writes anymore.
+			cmd.Process.Kill()
+		}
+	}()
+
+	writeErr := writePluginEvents(ctx, stdin, events, reading)
+	stdin.Close()
+	if writeErr != nil {
+		cmd.Process.Kill()
+	}
+	<-reading
+	waitErr := cmd.Wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case readErr != nil:
+		return readErr
+	case waitErr != nil:
+		return waitErr
+	}
+	return writeErr
+}
+
+// Write events as JSON lines until there are no more, the plugin stops reading actions or ctx is done.
+func writePluginEvents(ctx context.Context, w io.Writer, events <-chan WatchEvent, reading <-chan struct{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			line := pluginEvent{Kind: event.Kind.String(), Board: event.Board, Thread: event.Thread, Posts: event.Posts}
+			if event.Err != nil {
+				line.Error = event.Err.Error()
+			}
+			if err := enc.Encode(&line); err != nil {
+				return err
+			}
+		case <-reading:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Decode each line the plugin writes as an action and handle it.
+func readPluginActions(r io.Reader, handle func(PluginAction) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxPluginLine)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var action PluginAction
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			return fmt.Errorf("Plugin wrote %q, which isn't an action: %v", scanner.Text(), err)
+		}
+		if err := handle(action); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,80 @@
+package fourchan
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// Stands in for a plugin, run as its own process by pluginCommand.
+func TestPluginProcess(t *testing.T) {
+	if os.Getenv("FOURCHAN_TEST_PLUGIN") != "1" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var event pluginEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			os.Exit(2)
+		}
+		if event.Kind == "error" {
+			fmt.Println("not json")
+		}
+		for _, post := range event.Posts {
+			fmt.Printf(`{"action": "download", "board": %q, "thread": %d, "post": %d, "data": {"ext": %q}}`+"\n",
+				event.Board, event.Thread, post.PostNumber, post.FileExt)
+		}
+	}
+	os.Exit(0)
+}
+
+func pluginCommand() *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestPluginProcess$")
+	cmd.Env = append(os.Environ(), "FOURCHAN_TEST_PLUGIN=1")
+	return cmd
+}
+
+func TestRunPlugin(t *testing.T) {
+	events := make(chan WatchEvent, 2)
+	events <- WatchEvent{Kind: WatchNewPosts, Board: "g", Thread: 1, Posts: []Post{{Meta: Meta{PostNumber: 2, FileExt: ".png"}}, {Meta: Meta{PostNumber: 3}}}}
+	events <- WatchEvent{Kind: WatchArchived, Board: "g", Thread: 1}
+	close(events)
+
+	var actions []PluginAction
+	err := RunPlugin(context.Background(), pluginCommand(), events, func(action PluginAction) error {
+		actions = append(actions, action)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[0].Action != "download" || actions[0].Board != "g" || actions[0].Thread != 1 || actions[0].Post != 2 ||
+		string(actions[0].Data) != `{"ext": ".png"}` || actions[1].Post != 3 {
+		t.Fatalf("bad actions %+v", actions)
+	}
+
+	// A failing handler stops the plugin.
+	failed := errors.New("disk full")
+	events = make(chan WatchEvent, 1)
+	events <- WatchEvent{Kind: WatchNewPosts, Board: "g", Thread: 1, Posts: []Post{{}}}
+	if err = RunPlugin(context.Background(), pluginCommand(), events, func(PluginAction) error { return failed }); err != failed {
+		t.Fatalf("expected the handler's error, got %v", err)
+	}
+
+	events = make(chan WatchEvent, 1)
+	events <- WatchEvent{Kind: WatchError, Board: "g", Thread: 1, Err: errors.New("down")}
+	if err = RunPlugin(context.Background(), pluginCommand(), events, func(PluginAction) error { return nil }); err == nil {
+		t.Fatal("expected an error for a line that isn't an action")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = RunPlugin(ctx, pluginCommand(), make(chan WatchEvent), func(PluginAction) error { return nil }); err != context.Canceled {
+		t.Fatalf("expected the context's error, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package fourchan
+
+import (
+	"time"
+)
+
+// Where a thread sits in a board's index.
+type BoardPosition struct {
+	// The index page the thread is on, starting at 1.
+	Page int
+	// Position of the thread on its page, starting at 0.
+	Index int
+	// Number of index pages the board has.
+	Pages int
+	// Number of threads per index page.
+	PerPage int
+}
+
+// How many threads are above this one on the board.
+func (p BoardPosition) Rank() int {
+	return (p.Page-1)*p.PerPage + p.Index
+}
+
+// How close a thread is to being pruned.
+type PruneEstimate struct {
+	// Number of bumps or new threads that push this one off the board.
+	Remaining int
+	// Fraction of the board above the thread, 0 at the top and 1 at the very end of the last page.
+	Depth float64
+	// Expected time until the thread is pruned, zero if the bump rate is unknown.
+	// For bumpable threads this is only a lower bound, a reply moves them back to the top.
+	TimeLeft time.Duration
+	// Can the thread still be bumped back up?
+	Bumpable bool
+}
+
+// Estimate how close a thread at pos is to falling off the board.
+// bumpsPerHour is how many threads get bumped or created on the board per hour, 0 if unknown.
+func EstimatePrune(pos BoardPosition, bumpLimited bool, bumpsPerHour float64) PruneEstimate {
+	slots := pos.Pages * pos.PerPage
+	if slots <= 0 {
+		return PruneEstimate{Bumpable: !bumpLimited}
+	}
+
+	rank := pos.Rank()
+	if rank >= slots {
+		rank = slots - 1
+	}
+
+	estimate := PruneEstimate{
+		Remaining: slots - rank,
+		Depth:     float64(rank+1) / float64(slots),
+		Bumpable:  !bumpLimited,
+	}
+	if bumpsPerHour > 0 {
+		estimate.TimeLeft = time.Duration(float64(estimate.Remaining) / bumpsPerHour * float64(time.Hour))
+	}
+
+	return estimate
+}
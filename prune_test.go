@@ -0,0 +1,26 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimatePrune(t *testing.T) {
+	top := EstimatePrune(BoardPosition{Page: 1, Index: 0, Pages: 10, PerPage: 15}, false, 0)
+	if top.Remaining != 150 || top.TimeLeft != 0 || !top.Bumpable {
+		t.Fatalf("bad top estimate %+v", top)
+	}
+
+	last := EstimatePrune(BoardPosition{Page: 10, Index: 14, Pages: 10, PerPage: 15}, true, 30)
+	if last.Remaining != 1 || last.Depth != 1 || last.Bumpable {
+		t.Fatalf("bad last estimate %+v", last)
+	}
+	if last.TimeLeft != 2*time.Minute {
+		t.Fatalf("%v != 2m", last.TimeLeft)
+	}
+
+	middle := EstimatePrune(BoardPosition{Page: 6, Index: 4, Pages: 10, PerPage: 15}, true, 60)
+	if middle.Remaining != 71 || middle.TimeLeft != 71*time.Minute {
+		t.Fatalf("bad middle estimate %+v", middle)
+	}
+}
@@ -0,0 +1,59 @@
+package fourchan
+
+import (
+	"sync"
+)
+
+// Ways a board differs from the others that URLs and requests have to allow for.
+type BoardQuirks struct {
+	// Files are served under the names they were posted with rather than renamed, as on /f/.
+	OriginalFileNames bool
+	// Files have no thumbnails.
+	NoThumbnails bool
+	// The board keeps no archive, so there's no archive.json to ask for.
+	NoArchive bool
+	// Posters get no IDs, so an id that comes through anyway, e.g. from a third party archive, is dropped.
+	NoPosterIDs bool
+	// Posters get no country or board flags, so any that come through are dropped.
+	NoFlags bool
+}
+
+var (
+	quirksMu sync.RWMutex
+	// The quirks of the boards that have any.
+	boardQuirks = map[string]BoardQuirks{
+		"b": {NoArchive: true},
+		"f": {OriginalFileNames: true, NoThumbnails: true, NoArchive: true, NoPosterIDs: true, NoFlags: true},
+	}
+)
+
+// The quirks of a board, the zero value for a board without any.
+func QuirksFor(board string) BoardQuirks {
+	quirksMu.RLock()
+	defer quirksMu.RUnlock()
+	return boardQuirks[board]
+}
+
+// Set the quirks of a board, replacing the ones the package knows of, for when 4chan changes a board
+// before this package catches up. Settings apply to every client.
+func SetBoardQuirks(board string, quirks BoardQuirks) {
+	quirksMu.Lock()
+	defer quirksMu.Unlock()
+	boardQuirks[board] = quirks
+}
+
+// Drop what the board's quirks say its posts can't have, as posts are decoded.
+func (q BoardQuirks) fixPosts(posts []Post) {
+	for i := range posts {
+		q.fixPost(&posts[i])
+	}
+}
+
+func (q BoardQuirks) fixPost(p *Post) {
+	if q.NoPosterIDs {
+		p.AdminId = ""
+	}
+	if q.NoFlags {
+		p.CountryCode, p.Country, p.TrollCountry = "", "", ""
+	}
+}
@@ -0,0 +1,79 @@
+package fourchan
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBoardQuirks(t *testing.T) {
+	flash := &Post{Meta: Meta{PostNumber: 1, HasFile: true, RenamedFileName: 100, FileExt: ".swf", OrigFileName: "cool game"}}
+	if url := flash.ImageURL("f"); url != "https://i.4cdn.org/f/cool%20game.swf" {
+		t.Fatalf("bad /f/ URL %s", url)
+	}
+	if url := flash.ThumbnailURL("f"); url != "" {
+		t.Fatalf("expected no thumbnail, got %s", url)
+	}
+	if url := flash.ImageURL("g"); url != "https://i.4cdn.org/g/100.swf" {
+		t.Fatalf("bad /g/ URL %s", url)
+	}
+
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.EscapedPath())
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+	client := NewClient(WithAPIURL(server.URL), WithMediaURL(server.URL), WithRateLimit(0))
+
+	var buf bytes.Buffer
+	if err := client.DownloadImage(context.Background(), "f", flash, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DownloadThumbnail(context.Background(), "f", flash, &buf); err == nil {
+		t.Fatal("downloaded a thumbnail from /f/")
+	}
+	if _, err := client.LoadArchivedThreadIDs(context.Background(), "f"); !isNotFound(err) {
+		t.Fatalf("expected no archive, got %v", err)
+	}
+	if len(requested) != 1 || requested[0] != "/f/cool%20game.swf" {
+		t.Fatalf("bad requests %v", requested)
+	}
+
+	plan := (&Thread{Board: "f", Posts: []Post{*flash}}).PlanDownloads("", &DownloadOptions{Thumbnails: true})
+	if len(plan.Files) != 1 || plan.Files[0].Thumbnail {
+		t.Fatalf("bad /f/ plan %+v", plan.Files)
+	}
+
+	defer SetBoardQuirks("g", QuirksFor("g"))
+	SetBoardQuirks("g", BoardQuirks{NoArchive: true})
+	if _, err := client.LoadArchivedThreadIDs(context.Background(), "g"); !isNotFound(err) || len(requested) != 1 {
+		t.Fatalf("expected the override to apply, got %v", err)
+	}
+}
+
+func TestBoardQuirksDecode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"posts": [{"no": 1, "id": "abcd1234", "country": "US", "country_name": "United States"}]}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	flash, err := client.LoadThread(context.Background(), "f", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := flash.Posts[0]; op.AdminId != "" || op.CountryCode != "" || op.Country != "" {
+		t.Fatalf("kept an ID or flag on /f/: %+v", op.Meta)
+	}
+
+	pol, err := client.LoadThread(context.Background(), "pol", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := pol.Posts[0]; op.AdminId != "abcd1234" || op.CountryCode != "US" {
+		t.Fatalf("dropped an ID or flag on /pol/: %+v", op.Meta)
+	}
+}
@@ -0,0 +1,101 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+)
+
+// Resolve the quotes in a thread that point outside it, see Client.ResolveQuotes.
+func ResolveQuotes(t *Thread) (map[PostID][]PostLocation, error) {
+	return defaultClient().ResolveQuotes(context.Background(), t)
+}
+
+// Find the posts outside the thread that its posts quote, keyed by the number of the quoting post.
+// Links to other threads are followed by loading those threads, which falls back to the client's archives
+// for threads that are gone. Dead links, which no longer say which thread they were in, are looked up with FindPost.
+// Each thread is loaded once. Quotes that can't be found anywhere are left out, so this complements ReplyMap
+// rather than replacing it.
+func (c *Client) ResolveQuotes(ctx context.Context, t *Thread) (map[PostID][]PostLocation, error) {
+	threads := map[ThreadRef]*Thread{}
+	resolved := map[PostID][]PostLocation{}
+
+	for i := range t.Posts {
+		nodes := t.Posts[i].CommentNodes()
+		t.MarkDeadLinks(nodes)
+
+		var quoted []CommentNode
+		seen := map[string]bool{}
+		walkNodes(nodes, func(n *CommentNode) bool {
+			if n.Kind != NodeQuoteLink && n.Kind != NodeDeadLink || n.Post == 0 {
+				return true
+			}
+			if n.Board == "" {
+				n.Board = t.Board
+			}
+			key := fmt.Sprintf("%s/%d", n.Board, n.Post)
+			if !seen[key] && (n.Dead || !t.links(n.Board, n.Thread)) {
+				seen[key] = true
+				quoted = append(quoted, *n)
+			}
+			return true
+		})
+
+		for _, quote := range quoted {
+			location, err := c.resolveQuote(ctx, threads, quote)
+			if err != nil {
+				return nil, err
+			}
+			if location.Post != nil {
+				resolved[t.Posts[i].PostNumber] = append(resolved[t.Posts[i].PostNumber], location)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// Does the link point into this thread?
+func (t *Thread) links(board string, thread ThreadID) bool {
+	if board != t.Board || len(t.Posts) == 0 {
+		return false
+	}
+	return thread == 0 || thread == t.Posts[0].PostNumber
+}
+
+// Find one quoted post, the location is empty when it can't be found.
+// Dead posts are only looked for in archives, the thread they were in no longer has them.
+func (c *Client) resolveQuote(ctx context.Context, threads map[ThreadRef]*Thread, quote CommentNode) (PostLocation, error) {
+	if quote.Dead || quote.Thread == 0 {
+		location, err := c.FindPost(ctx, quote.Board, quote.Post)
+		if _, missing := err.(PostNotFoundError); missing {
+			return PostLocation{}, nil
+		}
+		if location.Board == "" {
+			location.Board = quote.Board
+		}
+		return location, err
+	}
+
+	key := ThreadRef{quote.Board, quote.Thread}
+	thread, ok := threads[key]
+	if !ok {
+		var err error
+		thread, err = c.LoadThread(ctx, quote.Board, quote.Thread)
+		if _, missing := err.(ThreadNotFoundError); missing {
+			err = nil
+		}
+		if err != nil {
+			return PostLocation{}, err
+		}
+		threads[key] = thread
+	}
+	if thread == nil {
+		return PostLocation{}, nil
+	}
+
+	for i := range thread.Posts {
+		if thread.Posts[i].PostNumber == quote.Post {
+			return PostLocation{Board: quote.Board, Thread: quote.Thread, Post: thread.Posts[i].Clone()}, nil
+		}
+	}
+	return PostLocation{}, nil
+}
@@ -0,0 +1,73 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// An archive that can find posts in the threads it has.
+type fakeSearcher struct {
+	fakeArchive
+}
+
+func (a fakeSearcher) FindByMD5(ctx context.Context, board, md5 string) ([]PostLocation, error) {
+	return nil, nil
+}
+
+func (a fakeSearcher) FindPost(ctx context.Context, board string, no uint64) (PostLocation, error) {
+	for _, thread := range a.fakeArchive {
+		for i := range thread.Posts {
+			if thread.Posts[i].PostNumber == no {
+				return PostLocation{Board: board, Thread: thread.Posts[0].PostNumber, Post: &thread.Posts[i]}, nil
+			}
+		}
+	}
+	return PostLocation{}, PostNotFoundError{board, no}
+}
+
+func TestResolveQuotes(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/g/thread/9.json" {
+			fmt.Fprint(w, `{"posts": [{"no": 9}, {"no": 10, "resto": 9, "com": "other thread"}]}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	archive := fakeSearcher{fakeArchive{"g/3": {Posts: []Post{{Meta: Meta{PostNumber: 3}}, {Meta: Meta{PostNumber: 4}, Comment: "deleted"}}}}}
+	client := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithHTTPClient(server.Client()), WithArchiveFallback(archive))
+
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 5}},
+		{Meta: Meta{PostNumber: 7}, Comment: `<a href="#p5" class="quotelink">&gt;&gt;5</a> ` +
+			`<a href="/g/thread/9#p10" class="quotelink">&gt;&gt;10</a> <span class="deadlink">&gt;&gt;4</span>`},
+		{Meta: Meta{PostNumber: 8}, Comment: `<a href="/g/thread/9#p10" class="quotelink">&gt;&gt;10</a> ` +
+			`<a href="/g/thread/9#p11" class="quotelink">&gt;&gt;11</a> <a href="#p12" class="quotelink">&gt;&gt;12</a>`},
+	}}
+
+	resolved, err := client.ResolveQuotes(context.Background(), thread)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 2 || len(resolved[7]) != 2 || len(resolved[8]) != 1 {
+		t.Fatalf("bad quotes %+v", resolved)
+	}
+	if l := resolved[7][0]; l.Board != "g" || l.Thread != 9 || l.Post.Comment != "other thread" {
+		t.Fatalf("bad cross thread quote %+v", l)
+	}
+	if l := resolved[7][1]; l.Thread != 3 || l.Post.Comment != "deleted" {
+		t.Fatalf("bad archived quote %+v", l)
+	}
+	if l := resolved[8][0]; l.Post.PostNumber != 10 {
+		t.Fatalf("bad quote %+v", l)
+	}
+	if requests != 1 {
+		t.Fatalf("%d requests, thread 9 should be loaded once", requests)
+	}
+}
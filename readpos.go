@@ -0,0 +1,142 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// Where read positions are kept.
+// Positions are the number of the last post a consumer has seen in a thread.
+type ReadStore interface {
+	// The last post seen, 0 if the consumer hasn't read the thread.
+	LastRead(consumer, board string, thread uint64) (uint64, error)
+	// Record the last post seen.
+	SetLastRead(consumer, board string, thread, post uint64) error
+}
+
+// Key identifying a consumer's position in a thread.
+func readKey(consumer, board string, thread uint64) string {
+	return fmt.Sprintf("%s/%s/%d", consumer, board, thread)
+}
+
+// A ReadStore kept in memory.
+type MemoryReadStore struct {
+	mu        sync.Mutex
+	positions map[string]uint64
+}
+
+// Create an empty in memory store.
+func NewMemoryReadStore() *MemoryReadStore {
+	return &MemoryReadStore{positions: map[string]uint64{}}
+}
+
+// Implements ReadStore.
+func (s *MemoryReadStore) LastRead(consumer, board string, thread uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.positions[readKey(consumer, board, thread)], nil
+}
+
+// Implements ReadStore.
+func (s *MemoryReadStore) SetLastRead(consumer, board string, thread, post uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positions[readKey(consumer, board, thread)] = post
+	return nil
+}
+
+// A ReadStore persisted to a JSON file, replaced whole on every update so a crash never leaves it half written.
+type FileReadStore struct {
+	path string
+	mem  *MemoryReadStore
+}
+
+// Open a file backed store, the file is created on the first update.
+func OpenFileReadStore(path string) (*FileReadStore, error) {
+	s := &FileReadStore{path: path, mem: NewMemoryReadStore()}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &s.mem.positions); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Implements ReadStore.
+func (s *FileReadStore) LastRead(consumer, board string, thread uint64) (uint64, error) {
+	return s.mem.LastRead(consumer, board, thread)
+}
+
+// Implements ReadStore.
+func (s *FileReadStore) SetLastRead(consumer, board string, thread, post uint64) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+
+	s.mem.positions[readKey(consumer, board, thread)] = post
+	data, err := json.Marshal(s.mem.positions)
+	if err != nil {
+		return err
+	}
+	return saveFile(s.path, true, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Tracks how far one consumer has read in each thread.
+type ReadTracker struct {
+	Store    ReadStore
+	Consumer string
+}
+
+// The posts in the thread the consumer hasn't seen yet.
+func (r *ReadTracker) Unread(t *Thread) ([]Post, error) {
+	if len(t.Posts) == 0 {
+		return nil, nil
+	}
+	last, err := r.Store.LastRead(r.Consumer, t.Board, t.Posts[0].PostNumber)
+	if err != nil {
+		return nil, err
+	}
+	return t.UnreadPosts(last), nil
+}
+
+// Mark every post currently in the thread as seen.
+func (r *ReadTracker) MarkRead(t *Thread) error {
+	if len(t.Posts) == 0 {
+		return nil
+	}
+	return r.Store.SetLastRead(r.Consumer, t.Board, t.Posts[0].PostNumber, t.lastPostNumber())
+}
+
+// The highest post number in the thread.
+func (t *Thread) lastPostNumber() uint64 {
+	var last uint64
+	for i := range t.Posts {
+		if t.Posts[i].PostNumber > last {
+			last = t.Posts[i].PostNumber
+		}
+	}
+	return last
+}
+
+// The posts made after the post numbered since.
+func (t *Thread) UnreadPosts(since uint64) []Post {
+	var posts []Post
+	for _, post := range t.Posts {
+		if post.PostNumber > since {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
@@ -0,0 +1,50 @@
+package fourchan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "readpos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "read.json")
+
+	store, err := OpenFileReadStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker := &ReadTracker{Store: store, Consumer: "digest"}
+
+	thread := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1}}, {Meta: Meta{PostNumber: 2}}}}
+	unread, err := tracker.Unread(thread)
+	if err != nil || len(unread) != 2 {
+		t.Fatalf("bad unread %v %v", unread, err)
+	}
+	if err = tracker.MarkRead(thread); err != nil {
+		t.Fatal(err)
+	}
+
+	thread.Posts = append(thread.Posts, Post{Meta: Meta{PostNumber: 5}})
+
+	// Positions survive reopening the store and are kept per consumer.
+	store, err = OpenFileReadStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker = &ReadTracker{Store: store, Consumer: "digest"}
+	unread, err = tracker.Unread(thread)
+	if err != nil || len(unread) != 1 || unread[0].PostNumber != 5 {
+		t.Fatalf("bad unread %v %v", unread, err)
+	}
+
+	other := &ReadTracker{Store: store, Consumer: "reader"}
+	if unread, _ = other.Unread(thread); len(unread) != 3 {
+		t.Fatalf("positions shared between consumers: %v", unread)
+	}
+}
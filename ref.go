@@ -0,0 +1,79 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// A thread on a board.
+// It is comparable, so it can key maps and sets of watched or archived threads.
+type ThreadRef struct {
+	Board string   `json:"board"`
+	No    ThreadID `json:"no"`
+}
+
+// Format the reference as /board/no.
+func (r ThreadRef) String() string {
+	return fmt.Sprintf("/%s/%d", r.Board, r.No)
+}
+
+// Order references by board, then number.
+func refLess(a, b ThreadRef) bool {
+	if a.Board != b.Board {
+		return a.Board < b.Board
+	}
+	return a.No < b.No
+}
+
+// Find the thread a thread URL points at.
+func ParseThreadURL(url string) (ThreadRef, error) {
+	board, id, err := extractBoardAndThreadId(url)
+	if err != nil {
+		return ThreadRef{}, err
+	}
+	no, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return ThreadRef{}, URLMatchError{url}
+	}
+	return ThreadRef{board, no}, nil
+}
+
+// The board and OP of the thread, a thread without posts has no number.
+func (t *Thread) Ref() ThreadRef {
+	ref := ThreadRef{Board: t.Board}
+	if len(t.Posts) > 0 {
+		ref.No = t.Posts[0].PostNumber
+	}
+	return ref
+}
+
+// The thread the watcher polls.
+func (w *ThreadWatcher) Ref() ThreadRef {
+	return ThreadRef{w.Board, w.ID}
+}
+
+// The thread the event is about.
+func (e WatchEvent) Ref() ThreadRef {
+	return ThreadRef{e.Board, e.Thread}
+}
+
+// The thread the post is in.
+func (l PostLocation) Ref() ThreadRef {
+	return ThreadRef{l.Board, l.Thread}
+}
+
+// Load the referenced thread.
+func LoadThreadRef(ref ThreadRef) (*Thread, error) {
+	return defaultClient().LoadThreadRef(context.Background(), ref)
+}
+
+// Load the referenced thread, falling back to the archives like LoadThreadById.
+func (c *Client) LoadThreadRef(ctx context.Context, ref ThreadRef) (*Thread, error) {
+	return c.LoadThread(ctx, ref.Board, ref.No)
+}
+
+// A watcher for the referenced thread, with the default settings.
+func NewThreadWatcher(ref ThreadRef) *ThreadWatcher {
+	return &ThreadWatcher{Board: ref.Board, ID: ref.No}
+}
@@ -0,0 +1,31 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestParseThreadURL(t *testing.T) {
+	ref, err := ParseThreadURL("https://boards.4chan.org/g/thread/123#p456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != (ThreadRef{"g", 123}) || ref.String() != "/g/123" {
+		t.Fatalf("bad ref %v", ref)
+	}
+
+	if _, err = ParseThreadURL("https://example.com/g/thread/123"); err == nil {
+		t.Fatal("parsed a URL that isn't 4chan's")
+	}
+}
+
+func TestThreadRefKeys(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 123}}}}
+	watcher := NewThreadWatcher(ThreadRef{"g", 123})
+	seen := map[ThreadRef]bool{thread.Ref(): true}
+	if !seen[watcher.Ref()] || !seen[(PostLocation{Board: "g", Thread: 123}).Ref()] {
+		t.Fatalf("refs don't match %v %v", thread.Ref(), watcher.Ref())
+	}
+	if (&Thread{Board: "g"}).Ref() != (ThreadRef{Board: "g"}) {
+		t.Fatal("empty thread has a number")
+	}
+}
@@ -0,0 +1,81 @@
+package fourchan
+
+import (
+	"context"
+	"sort"
+)
+
+// What refreshing one thread found.
+type Refresh struct {
+	Ref ThreadRef
+	// The reloaded thread, nil when loading it failed.
+	Thread *Thread
+	// Why the thread couldn't be loaded, ThreadNotFoundError when it is gone.
+	Err error
+}
+
+// Reload the threads that changed since the given snapshots, see Client.RefreshAll.
+func RefreshAll(known map[ThreadRef]*Thread) ([]Refresh, error) {
+	return defaultClient().RefreshAll(context.Background(), known)
+}
+
+// Reload the threads in a watch list that changed since their last snapshot.
+// One threads.json per board says which threads changed since each snapshot's ModifiedAt, so only those are loaded.
+// A nil snapshot always loads the thread. Threads no longer in threads.json are loaded too,
+// which tells whether they were archived or are gone. Immutable snapshots are never reloaded, see Thread.IsImmutable.
+// Only changed threads are returned, ordered by board and number.
+// The error is for a threads.json that couldn't be loaded, failures to load a thread are in its Refresh.
+func (c *Client) RefreshAll(ctx context.Context, known map[ThreadRef]*Thread) ([]Refresh, error) {
+	boards := map[string][]ThreadRef{}
+	for ref := range known {
+		boards[ref.Board] = append(boards[ref.Board], ref)
+	}
+
+	var stale []ThreadRef
+	for board, refs := range boards {
+		list, err := c.LoadThreadList(ctx, board)
+		if err != nil {
+			return nil, err
+		}
+		modified := map[ThreadID]uint64{}
+		for _, page := range list.Pages {
+			for _, summary := range page.Threads {
+				modified[summary.PostNumber] = summary.LastModified
+			}
+		}
+
+		for _, ref := range refs {
+			snapshot := known[ref]
+			if snapshot != nil && snapshot.IsImmutable() {
+				continue
+			}
+			if last, listed := modified[ref.No]; !listed || snapshot == nil || snapshot.lastModified() < last {
+				stale = append(stale, ref)
+			}
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return refLess(stale[i], stale[j]) })
+
+	refreshed := make([]Refresh, 0, len(stale))
+	for _, ref := range stale {
+		thread, err := c.LoadThreadRef(ctx, ref)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		refreshed = append(refreshed, Refresh{Ref: ref, Thread: thread, Err: err})
+	}
+	return refreshed, nil
+}
+
+// When the thread last changed as unix time, zero if that isn't known.
+// Only catalog.json and threads.json send last_modified, so threads loaded from their own
+// JSON go by the Last-Modified header they were served with instead.
+func (t *Thread) lastModified() uint64 {
+	if len(t.Posts) > 0 && t.Posts[0].LastModified != 0 {
+		return t.Posts[0].LastModified
+	}
+	if t.ModifiedAt.IsZero() {
+		return 0
+	}
+	return uint64(t.ModifiedAt.Unix())
+}
@@ -0,0 +1,67 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRefreshAll(t *testing.T) {
+	var loaded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/threads.json":
+			fmt.Fprint(w, `[{"page": 1, "threads": [{"no": 1, "last_modified": 100}, {"no": 2, "last_modified": 200}, {"no": 3, "last_modified": 300}]}]`)
+		case "/v/threads.json":
+			fmt.Fprint(w, `[{"page": 1, "threads": []}]`)
+		case "/g/thread/2.json":
+			loaded = append(loaded, r.URL.Path)
+			w.Header().Set("Last-Modified", time.Unix(200, 0).UTC().Format(http.TimeFormat))
+			fmt.Fprint(w, `{"posts": [{"no": 2}]}`)
+		case "/g/thread/3.json":
+			loaded = append(loaded, r.URL.Path)
+			w.Header().Set("Last-Modified", time.Unix(300, 0).UTC().Format(http.TimeFormat))
+			fmt.Fprint(w, `{"posts": [{"no": 3}]}`)
+		default:
+			loaded = append(loaded, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	known := map[ThreadRef]*Thread{
+		{"g", 1}: {Posts: []Post{{Meta: Meta{PostNumber: 1}}}, ModifiedAt: time.Unix(100, 0)},
+		{"g", 2}: {Posts: []Post{{Meta: Meta{PostNumber: 2}}}, ModifiedAt: time.Unix(150, 0)},
+		{"g", 3}: nil,
+		{"v", 4}: {Posts: []Post{{Meta: Meta{PostNumber: 4}}}, ModifiedAt: time.Unix(400, 0)},
+		{"v", 5}: {Posts: []Post{{Meta: Meta{PostNumber: 5, Archived: true}}}},
+	}
+	refreshed, err := newTestClient(server).RefreshAll(context.Background(), known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(refreshed) != 3 || len(loaded) != 3 {
+		t.Fatalf("bad refresh %+v, loaded %v", refreshed, loaded)
+	}
+	if r := refreshed[0]; r.Ref != (ThreadRef{"g", 2}) || r.Err != nil || r.Thread.ModifiedAt.Unix() != 200 {
+		t.Fatalf("bad refresh %+v", r)
+	}
+	if r := refreshed[1]; r.Ref != (ThreadRef{"g", 3}) || r.Thread == nil {
+		t.Fatalf("bad refresh %+v", r)
+	}
+	if r := refreshed[2]; r.Ref != (ThreadRef{"v", 4}) || r.Err != (ThreadNotFoundError{"v", "4"}) {
+		t.Fatalf("bad refresh %+v", r)
+	}
+
+	// The reloaded snapshots are up to date, going by their Last-Modified.
+	known[ThreadRef{"g", 2}], known[ThreadRef{"g", 3}] = refreshed[0].Thread, refreshed[1].Thread
+	delete(known, ThreadRef{"v", 4})
+	loaded = nil
+	if refreshed, err = newTestClient(server).RefreshAll(context.Background(), known); err != nil || len(refreshed) != 0 || len(loaded) != 0 {
+		t.Fatalf("up to date threads reloaded %+v, loaded %v", refreshed, loaded)
+	}
+}
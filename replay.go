@@ -0,0 +1,83 @@
+package fourchan
+
+import (
+	"context"
+	"time"
+)
+
+// Options for replaying a thread.
+type ReplayOptions struct {
+	// How many times faster than real time to replay, e.g. 60 for a minute of the thread per second.
+	// Zero replays without waiting, as fast as the events are read.
+	Speed float64
+}
+
+// Replay a loaded or archived thread as a ThreadWatcher would have seen it, for testing consumers of
+// watch events and running demos offline. Each post arrives in a WatchNewPosts event, posts made in the
+// same second together, and an archived thread ends with WatchArchived.
+// Deleted posts are gone from the thread, so they are never replayed.
+// The returned channel is closed once ctx is done or every event has been sent.
+func ReplayThread(ctx context.Context, t *Thread, opts *ReplayOptions) <-chan WatchEvent {
+	if opts == nil {
+		opts = &ReplayOptions{}
+	}
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		replayThread(ctx, t.Clone(), opts.Speed, events)
+	}()
+	return events
+}
+
+func replayThread(ctx context.Context, final *Thread, speed float64, events chan<- WatchEvent) {
+	if len(final.Posts) == 0 {
+		return
+	}
+	ref := final.Ref()
+	send := func(event WatchEvent) bool {
+		event.Board, event.Thread = ref.Board, ref.No
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// The snapshots before the end share these posts, with the OP as it was while the thread was live.
+	live := append([]Post(nil), final.Posts...)
+	live[0].Archived = false
+	live[0].ArchivedOn = 0
+
+	last := live[0].UnixTime
+	for start := 0; start < len(live); {
+		end := start + 1
+		for end < len(live) && live[end].UnixTime == live[start].UnixTime {
+			end++
+		}
+
+		if speed > 0 && live[start].UnixTime > last {
+			wait := time.Duration(float64(time.Duration(live[start].UnixTime-last)*time.Second) / speed)
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		last = live[start].UnixTime
+
+		snapshot := *final
+		snapshot.Posts = live[:end:end]
+		snapshot.ModifiedAt = time.Unix(int64(last), 0)
+		if !send(WatchEvent{Kind: WatchNewPosts, Posts: live[start:end:end], Snapshot: &snapshot}) {
+			return
+		}
+		start = end
+	}
+
+	if final.Posts[0].Archived {
+		send(WatchEvent{Kind: WatchArchived, Snapshot: final})
+	}
+}
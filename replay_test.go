@@ -0,0 +1,62 @@
+package fourchan
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReplayThread(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, UnixTime: 1000, Archived: true, ArchivedOn: 5000}},
+		{Meta: Meta{PostNumber: 2, UnixTime: 1001}},
+		{Meta: Meta{PostNumber: 3, UnixTime: 1001}},
+		{Meta: Meta{PostNumber: 4, UnixTime: 1002}},
+	}}
+
+	start := time.Now()
+	events := ReplayThread(context.Background(), thread, &ReplayOptions{Speed: 100})
+	var kinds []WatchEventKind
+	var sizes []int
+	for event := range events {
+		if event.Board != "g" || event.Thread != 1 {
+			t.Fatalf("bad event %+v", event)
+		}
+		kinds = append(kinds, event.Kind)
+		sizes = append(sizes, len(event.Posts))
+		if event.Kind == WatchNewPosts && event.Snapshot.Posts[0].Archived {
+			t.Fatalf("archived before the end %+v", event.Snapshot.Posts[0])
+		}
+		if event.Kind == WatchArchived && !event.Snapshot.Posts[0].Archived {
+			t.Fatal("final snapshot isn't archived")
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("two seconds at 100x took only %v", elapsed)
+	}
+	want := []WatchEventKind{WatchNewPosts, WatchNewPosts, WatchNewPosts, WatchArchived}
+	if len(kinds) != len(want) || kinds[0] != want[0] || kinds[3] != want[3] || sizes[0] != 1 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("bad events %v %v", kinds, sizes)
+	}
+	if !thread.Posts[0].Archived {
+		t.Fatal("replay changed the thread")
+	}
+}
+
+func TestReplayThreadCancel(t *testing.T) {
+	thread := &Thread{Posts: []Post{{Meta: Meta{PostNumber: 1, UnixTime: 0}}, {Meta: Meta{PostNumber: 2, UnixTime: 3600}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	events := ReplayThread(ctx, thread, &ReplayOptions{Speed: 1})
+	if event := nextEvent(t, events); event.Kind != WatchNewPosts {
+		t.Fatalf("bad event %+v", event)
+	}
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("event after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events not closed after cancel")
+	}
+}
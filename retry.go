@@ -0,0 +1,104 @@
+package fourchan
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Try requests that fail with a network error, a 5xx or a 429 again, up to attempts times in all.
+// The first retry waits backoff, each one after that twice as long, or as long as a 429's Retry-After asks.
+// Retries go through the rate limiter like any other request.
+func WithRetries(attempts int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.attempts = attempts
+		c.backoff = backoff
+	}
+}
+
+// Give each operation, e.g. loading a thread, at most d in all: every attempt, the waits between retries
+// and reading the response. A retry that couldn't finish waiting in time isn't made, the last failure
+// is returned instead. Timeouts for single attempts belong on the http.Client given to WithHTTPClient.
+func WithOperationTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.operationTimeout = d
+	}
+}
+
+// Send a request, retrying it as the client is configured to, within the operation timeout.
+func (c *Client) do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	cancel := func() {}
+	if c.operationTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.operationTimeout)
+	}
+	resp, err := c.doRetries(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The body is read within the operation too, so the timeout ends once it's closed.
+	resp.Body = &cancelBody{resp.Body, cancel}
+	return resp, nil
+}
+
+func (c *Client) doRetries(ctx context.Context, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.doOnce(ctx, req)
+		if attempt >= c.attempts || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff << uint(attempt-1)
+		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+			if wait := retryAfter(resp.Header.Get("Retry-After")); wait > delay {
+				delay = wait
+			}
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Send a request once the rate limiter allows it.
+func (c *Client) doOnce(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.httpClient.Do(req.WithContext(ctx))
+}
+
+// Could trying again help?
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// A response body that ends its operation's timeout once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
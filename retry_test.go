@@ -0,0 +1,73 @@
+package fourchan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientRetries(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			http.Error(w, "down", http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{"posts": [{"no": 1}]}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithRetries(3, time.Millisecond))
+	if _, err := c.LoadThread(context.Background(), "g", 1); err != nil || requests != 3 {
+		t.Fatalf("expected success on the third try, got %v after %d", err, requests)
+	}
+
+	// Not found isn't worth retrying.
+	atomic.StoreInt32(&requests, 0)
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer notFound.Close()
+	c = NewClient(WithAPIURL(notFound.URL), WithRateLimit(0), WithRetries(3, time.Millisecond))
+	if _, err := c.LoadThread(context.Background(), "g", 1); err != (ThreadNotFoundError{"g", "1"}) || requests != 1 {
+		t.Fatalf("expected one request and not found, got %v after %d", err, requests)
+	}
+}
+
+func TestClientOperationTimeout(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The second retry would wait past the budget, so the last failure is returned early.
+	c := NewClient(WithAPIURL(server.URL), WithRateLimit(0), WithRetries(10, 40*time.Millisecond), WithOperationTimeout(100*time.Millisecond))
+	start := time.Now()
+	_, err := c.LoadThread(context.Background(), "g", 1)
+	if se, ok := err.(ServerError); !ok || se.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last failure, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond || requests != 2 {
+		t.Fatalf("took %v and %d requests", elapsed, requests)
+	}
+
+	// The timeout covers reading the response too, and ends once it's read.
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"posts": [{"no": 1}]}`))
+	}))
+	defer ok.Close()
+	c = NewClient(WithAPIURL(ok.URL), WithRateLimit(0), WithOperationTimeout(time.Second))
+	if _, err = c.LoadThread(context.Background(), "g", 1); err != nil {
+		t.Fatal(err)
+	}
+}
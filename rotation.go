@@ -0,0 +1,117 @@
+package fourchan
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// How often a SnapshotRotation takes a snapshot unless its Interval says otherwise.
+const DefaultRotationInterval = time.Hour
+
+// Periodic snapshots of a board kept in a directory, the newest Keep of them, for a time series of
+// the board to analyze. Run one per board and schedule, e.g. hourly keeping a day and daily keeping a month.
+type SnapshotRotation struct {
+	// The board to snapshot.
+	Board string
+	// Where the snapshots are kept, named like g-20161014T150405.123456789Z.json after when they were taken.
+	// Use a directory per schedule, rotations of the same board in one directory prune each other's snapshots.
+	Dir string
+	// How often Run takes a snapshot, defaults to DefaultRotationInterval.
+	Interval time.Duration
+	// How many snapshots to keep, the oldest are removed first. Zero keeps them all.
+	Keep int
+	// Called by Run with each snapshot that failed, as Run carries on. Nil ignores failures.
+	OnError func(error)
+	// The client to take snapshots with, defaults to DefaultClient.
+	Client *Client
+}
+
+// The time format in snapshot file names.
+// The fraction is fixed width so names sort in time order, and snapshots taken within a second of
+// each other don't overwrite one another.
+const rotationTimeFormat = "20060102T150405.000000000Z"
+
+// Take a snapshot every Interval, starting right away, until ctx is done, which is the error returned.
+func (r *SnapshotRotation) Run(ctx context.Context) error {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultRotationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Rotate(ctx); err != nil && ctx.Err() == nil && r.OnError != nil {
+			r.OnError(err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Take one snapshot now, save it and remove the ones past Keep, returning where it was saved.
+// A snapshot missing threads that failed to load is still saved, and their BatchError returned with it.
+func (r *SnapshotRotation) Rotate(ctx context.Context) (string, error) {
+	client := r.Client
+	if client == nil {
+		client = defaultClient()
+	}
+
+	snapshot, err := client.SnapshotBoard(ctx, r.Board)
+	if _, partial := err.(BatchError); err != nil && !partial {
+		return "", err
+	}
+	path := filepath.Join(r.Dir, r.Board+"-"+snapshot.TakenAt.UTC().Format(rotationTimeFormat)+".json")
+	if saveErr := snapshot.Save(path); saveErr != nil {
+		return "", saveErr
+	}
+	if pruneErr := r.prune(); pruneErr != nil {
+		return path, pruneErr
+	}
+	return path, err
+}
+
+// The snapshots in Dir, oldest first.
+func (r *SnapshotRotation) Snapshots() ([]string, error) {
+	entries, err := ioutil.ReadDir(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Snapshots named before the fraction was added are kept track of too.
+	name := regexp.MustCompile(`^` + regexp.QuoteMeta(r.Board) + `-\d{8}T\d{6}(\.\d{9})?Z\.json$`)
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && name.MatchString(entry.Name()) {
+			paths = append(paths, filepath.Join(r.Dir, entry.Name()))
+		}
+	}
+	// The timestamps sort in time order.
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Remove the oldest snapshots past Keep.
+func (r *SnapshotRotation) prune() error {
+	if r.Keep <= 0 {
+		return nil
+	}
+	paths, err := r.Snapshots()
+	if err != nil {
+		return err
+	}
+	for len(paths) > r.Keep {
+		if err = os.Remove(paths[0]); err != nil {
+			return err
+		}
+		paths = paths[1:]
+	}
+	return nil
+}
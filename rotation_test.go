@@ -0,0 +1,71 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRotation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/catalog.json":
+			fmt.Fprint(w, `[{"page": 1, "threads": [{"no": 10}]}]`)
+		case "/g/thread/10.json":
+			fmt.Fprint(w, `{"posts": [{"no": 10}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "fourchan-rotation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"g-20160101T000000Z.json", "g-20160102T000000Z.json", "g-20160103T000000Z.json", "v-20160101T000000Z.json", "notes.txt"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644)
+	}
+
+	rotation := &SnapshotRotation{Board: "g", Dir: dir, Keep: 2, Client: newTestClient(server)}
+	path, err := rotation.Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "g-"+time.Now().UTC().Format("20060102T")); path[:len(want)] != want {
+		t.Fatalf("bad path %s", path)
+	}
+	if snapshot, err := LoadBoardSnapshot(path); err != nil || len(snapshot.Threads) != 1 {
+		t.Fatalf("bad snapshot %+v %v", snapshot, err)
+	}
+
+	paths, err := rotation.Snapshots()
+	if err != nil || len(paths) != 2 || paths[0] != filepath.Join(dir, "g-20160103T000000Z.json") || paths[1] != path {
+		t.Fatalf("bad snapshots after pruning %v %v", paths, err)
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 4 {
+		t.Fatalf("pruned other files: %v", entries)
+	}
+
+	again, err := rotation.Rotate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paths, _ = rotation.Snapshots(); len(paths) != 2 || paths[0] != path || paths[1] != again {
+		t.Fatalf("snapshots taken in the same second clashed: %v", paths)
+	}
+
+	// A zero Interval takes the default rather than panicking.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = rotation.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("run didn't stop with the context: %v", err)
+	}
+}
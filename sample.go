@@ -0,0 +1,68 @@
+package fourchan
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"time"
+)
+
+// Pick up to n threads per board for each UTC day they were started on, the same ones every time
+// for the same seed. Threads can come from anywhere: a catalog's previews, the archive, files on disk.
+// Each thread's chance depends only on the seed and the thread itself, not on what else is passed in,
+// so sampling a superset of the threads again picks the same threads out of those days plus maybe others.
+// The sample is ordered by board, day and thread number. Threads without posts are never picked.
+func SampleThreads(threads []*Thread, n int, seed int64) []*Thread {
+	type group struct {
+		board string
+		day   string
+	}
+	type candidate struct {
+		thread *Thread
+		rank   uint64
+	}
+
+	groups := map[group][]candidate{}
+	for _, thread := range threads {
+		if len(thread.Posts) == 0 {
+			continue
+		}
+		op := &thread.Posts[0]
+		g := group{thread.Board, time.Unix(int64(op.UnixTime), 0).UTC().Format("2006-01-02")}
+		groups[g] = append(groups[g], candidate{thread, sampleRank(seed, thread.Board, op.PostNumber)})
+	}
+
+	var sample []*Thread
+	for _, candidates := range groups {
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].rank != candidates[j].rank {
+				return candidates[i].rank < candidates[j].rank
+			}
+			return candidates[i].thread.Posts[0].PostNumber < candidates[j].thread.Posts[0].PostNumber
+		})
+		for i := 0; i < n && i < len(candidates); i++ {
+			sample = append(sample, candidates[i].thread)
+		}
+	}
+
+	sort.Slice(sample, func(i, j int) bool {
+		a, b := sample[i], sample[j]
+		if a.Board != b.Board {
+			return a.Board < b.Board
+		}
+		// Thread numbers count up, so they order days too.
+		return a.Posts[0].PostNumber < b.Posts[0].PostNumber
+	})
+	return sample
+}
+
+// Where a thread falls in the random order for the seed.
+func sampleRank(seed int64, board string, no PostID) uint64 {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+	binary.BigEndian.PutUint64(buf[8:], no)
+	h := sha256.New()
+	h.Write(buf[:])
+	h.Write([]byte(board))
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
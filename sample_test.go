@@ -0,0 +1,69 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampleThreads(t *testing.T) {
+	day := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	var threads []*Thread
+	for i := 0; i < 30; i++ {
+		board := "g"
+		if i%3 == 0 {
+			board = "v"
+		}
+		posted := day.Add(time.Duration(i) * 4 * time.Hour)
+		threads = append(threads, &Thread{Board: board, Posts: []Post{{Meta: Meta{PostNumber: uint64(100 + i), UnixTime: uint64(posted.Unix())}}}})
+	}
+	threads = append(threads, &Thread{Board: "g"})
+
+	refs := func(sample []*Thread) (rs []ThreadRef) {
+		for _, thread := range sample {
+			rs = append(rs, thread.Ref())
+		}
+		return rs
+	}
+
+	sample := SampleThreads(threads, 2, 42)
+	// 5 days with threads on each board, 2 of each.
+	if len(sample) != 20 {
+		t.Fatalf("sampled %d threads: %v", len(sample), refs(sample))
+	}
+	perDay := map[string]int{}
+	for i, thread := range sample {
+		perDay[thread.Board+time.Unix(int64(thread.Posts[0].UnixTime), 0).UTC().Format("0102")]++
+		if i > 0 && sample[i-1].Board == thread.Board && sample[i-1].Posts[0].PostNumber > thread.Posts[0].PostNumber {
+			t.Fatalf("out of order: %v", refs(sample))
+		}
+	}
+	for key, n := range perDay {
+		if n != 2 {
+			t.Fatalf("%d threads for %s", n, key)
+		}
+	}
+
+	// The same seed picks the same threads in any order, another seed doesn't.
+	reversed := make([]*Thread, len(threads))
+	for i, thread := range threads {
+		reversed[len(threads)-1-i] = thread
+	}
+	if a, b := refs(sample), refs(SampleThreads(reversed, 2, 42)); !sameRefs(a, b) {
+		t.Fatalf("sample changed with order\n%v\n%v", a, b)
+	}
+	if sameRefs(refs(sample), refs(SampleThreads(threads, 2, 7))) {
+		t.Fatal("seed makes no difference")
+	}
+}
+
+func sameRefs(a, b []ThreadRef) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package fourchan
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// How a thread is laid out on disk by Save.
+// Posts use the API's own encoding, so files can also be read by anything that reads thread JSON.
+type threadFile struct {
+	Board      string    `json:"board"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Archive    string    `json:"archive,omitempty"`
+	Posts      []Post    `json:"posts"`
+}
+
+// Write the thread to path as JSON, along with its board and when it was fetched.
+// The file is replaced in one step and flushed to disk, so neither readers nor a crash ever leave half a thread.
+func (t *Thread) Save(path string) error {
+	return saveFile(path, true, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(&threadFile{t.Board, t.FetchedAt, t.ModifiedAt, t.Archive, t.Posts})
+	})
+}
+
+// Load a thread written by Save.
+func LoadThreadFromFile(path string) (*Thread, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f threadFile
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	return &Thread{Posts: f.Posts, Board: f.Board, FetchedAt: f.FetchedAt, ModifiedAt: f.ModifiedAt, Archive: f.Archive}, nil
+}
@@ -0,0 +1,48 @@
+package fourchan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestThreadSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fourchan-snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fetched := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	thread := &Thread{Board: "g", FetchedAt: fetched, Archive: "archived.moe", Posts: []Post{
+		{Subject: "Desktop thread", Comment: "Post &amp; rate", Meta: Meta{PostNumber: 1, Sticky: true, Closed: true,
+			RenamedFileName: 1456789012345, FileExt: ".png", OrigFileName: "desk", HasFile: true}},
+		{Comment: "nice", Meta: Meta{PostNumber: 2, ReplyTo: 1, FileDeleted: true}},
+	}}
+
+	path := filepath.Join(dir, "1.json")
+	if err = thread.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadThreadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Board != "g" || !loaded.FetchedAt.Equal(fetched) || loaded.Archive != "archived.moe" || len(loaded.Posts) != 2 {
+		t.Fatalf("bad thread %+v", loaded)
+	}
+	op := loaded.Posts[0]
+	if !op.Sticky || !op.Closed || !op.HasFile || op.FullNewFileName != "1456789012345.png" || op.Comment != "Post &amp; rate" {
+		t.Fatalf("bad op %+v", op)
+	}
+	if !loaded.Posts[1].FileDeleted || loaded.Posts[1].ReplyTo != 1 {
+		t.Fatalf("bad reply %+v", loaded.Posts[1])
+	}
+
+	if _, err = LoadThreadFromFile(filepath.Join(dir, "2.json")); !os.IsNotExist(err) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package fourchan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The kind of pattern a SpamFinding describes.
+type SpamKind int
+
+const (
+	// The same file was posted many times in a short window.
+	SpamRepeatedFile SpamKind = iota
+	// The same comment was posted by many different poster IDs.
+	SpamRepeatedComment
+	// Posts arrived much faster than normal.
+	SpamPostRate
+)
+
+// Pretty print the kind.
+func (k SpamKind) String() string {
+	switch k {
+	case SpamRepeatedFile:
+		return "repeated file"
+	case SpamRepeatedComment:
+		return "repeated comment"
+	case SpamPostRate:
+		return "post rate"
+	}
+	return fmt.Sprintf("SpamKind(%d)", int(k))
+}
+
+// Thresholds for spam detection. Zero values use the defaults.
+type SpamOptions struct {
+	// Window files and post rates are measured over, defaults to 5 minutes.
+	Window time.Duration
+	// How many times a file must appear in one window, defaults to 3.
+	MinRepeats int
+	// How many distinct poster IDs must post the same comment, defaults to 3.
+	// Posts without an ID each count as a distinct poster.
+	MinPosters int
+	// Posts per minute in one window that count as a flood, defaults to 30.
+	MaxRate float64
+}
+
+// A probable spam wave.
+type SpamFinding struct {
+	Kind SpamKind
+	// Human readable summary.
+	Description string
+	// The posts involved, oldest first.
+	Posts []PostLocation
+	// Time of the first and last post involved.
+	Start, End time.Time
+}
+
+func (o *SpamOptions) withDefaults() SpamOptions {
+	opts := SpamOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Window <= 0 {
+		opts.Window = 5 * time.Minute
+	}
+	if opts.MinRepeats <= 0 {
+		opts.MinRepeats = 3
+	}
+	if opts.MinPosters <= 0 {
+		opts.MinPosters = 3
+	}
+	if opts.MaxRate <= 0 {
+		opts.MaxRate = 30
+	}
+	return opts
+}
+
+// Flag probable spam across the given threads, e.g. one thread or a window of a board.
+func DetectSpam(threads []*Thread, opts *SpamOptions) []SpamFinding {
+	o := opts.withDefaults()
+
+	var all []PostLocation
+	byMD5 := map[string][]PostLocation{}
+	byComment := map[string][]PostLocation{}
+	var md5s, comments []string
+	for _, thread := range threads {
+		op := firstPostNumber(thread)
+		for i := range thread.Posts {
+			post := &thread.Posts[i]
+			loc := PostLocation{thread.Board, op, post}
+			all = append(all, loc)
+
+			if post.FileMD5 != "" {
+				if _, ok := byMD5[post.FileMD5]; !ok {
+					md5s = append(md5s, post.FileMD5)
+				}
+				byMD5[post.FileMD5] = append(byMD5[post.FileMD5], loc)
+			}
+			if text := normalizeComment(post.Comment); len([]rune(text)) >= 10 {
+				if _, ok := byComment[text]; !ok {
+					comments = append(comments, text)
+				}
+				byComment[text] = append(byComment[text], loc)
+			}
+		}
+	}
+
+	var findings []SpamFinding
+
+	for _, md5 := range md5s {
+		posts := densestWindow(byMD5[md5], o.Window)
+		if len(posts) >= o.MinRepeats {
+			findings = append(findings, newSpamFinding(SpamRepeatedFile,
+				fmt.Sprintf("File %s posted %d times within %v", md5, len(posts), o.Window), posts))
+		}
+	}
+
+	for _, text := range comments {
+		posts := byComment[text]
+		posters := map[string]bool{}
+		anonymous := 0
+		for _, loc := range posts {
+			if loc.Post.AdminId == "" {
+				anonymous++
+			} else {
+				posters[loc.Post.AdminId] = true
+			}
+		}
+		if n := len(posters) + anonymous; n >= o.MinPosters {
+			sortByTime(posts)
+			findings = append(findings, newSpamFinding(SpamRepeatedComment,
+				fmt.Sprintf("Comment %q posted by %d posters", truncateWords(text, 40), n), posts))
+		}
+	}
+
+	posts := densestWindow(all, o.Window)
+	if rate := float64(len(posts)) / o.Window.Minutes(); rate > o.MaxRate {
+		findings = append(findings, newSpamFinding(SpamPostRate,
+			fmt.Sprintf("%d posts within %v (%.1f/min)", len(posts), o.Window, rate), posts))
+	}
+
+	return findings
+}
+
+// Flag probable spam in the thread.
+func (t *Thread) DetectSpam(opts *SpamOptions) []SpamFinding {
+	return DetectSpam([]*Thread{t}, opts)
+}
+
+func newSpamFinding(kind SpamKind, description string, posts []PostLocation) SpamFinding {
+	return SpamFinding{
+		Kind:        kind,
+		Description: description,
+		Posts:       posts,
+		Start:       posts[0].Post.PostTime(),
+		End:         posts[len(posts)-1].Post.PostTime(),
+	}
+}
+
+func sortByTime(posts []PostLocation) {
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Post.UnixTime < posts[j].Post.UnixTime
+	})
+}
+
+// The largest run of posts falling within one window, oldest first.
+func densestWindow(posts []PostLocation, window time.Duration) []PostLocation {
+	sorted := make([]PostLocation, len(posts))
+	copy(sorted, posts)
+	sortByTime(sorted)
+
+	seconds := uint64(window / time.Second)
+	if seconds == 0 {
+		seconds = 1
+	}
+	bestStart, bestEnd := 0, 0
+	start := 0
+	for end := range sorted {
+		for sorted[end].Post.UnixTime-sorted[start].Post.UnixTime >= seconds {
+			start++
+		}
+		if end+1-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, end+1
+		}
+	}
+	return sorted[bestStart:bestEnd]
+}
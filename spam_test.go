@@ -0,0 +1,51 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectSpam(t *testing.T) {
+	pasta := "buy cheap watches at example dot com"
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, UnixTime: 1000}},
+		{Meta: Meta{PostNumber: 2, UnixTime: 1010, FileMD5: "abc"}},
+		{Meta: Meta{PostNumber: 3, UnixTime: 1020, FileMD5: "abc"}},
+		{Meta: Meta{PostNumber: 4, UnixTime: 1030, FileMD5: "abc"}},
+		{Meta: Meta{PostNumber: 5, UnixTime: 5000, FileMD5: "def"}},
+		{Meta: Meta{PostNumber: 6, UnixTime: 9000, FileMD5: "def"}},
+		{Meta: Meta{PostNumber: 7, UnixTime: 9100, FileMD5: "def"}},
+		{Comment: pasta, Meta: Meta{PostNumber: 8, UnixTime: 9200, AdminId: "aaaa"}},
+		{Comment: pasta, Meta: Meta{PostNumber: 9, UnixTime: 9300, AdminId: "bbbb"}},
+		{Comment: pasta, Meta: Meta{PostNumber: 10, UnixTime: 9400, AdminId: "aaaa"}},
+		{Comment: pasta, Meta: Meta{PostNumber: 11, UnixTime: 9500, AdminId: "cccc"}},
+	}}
+
+	findings := thread.DetectSpam(nil)
+	kinds := map[SpamKind]SpamFinding{}
+	for _, finding := range findings {
+		if _, ok := kinds[finding.Kind]; ok {
+			t.Fatalf("duplicate %v finding: %+v", finding.Kind, findings)
+		}
+		kinds[finding.Kind] = finding
+	}
+
+	file, ok := kinds[SpamRepeatedFile]
+	if !ok || len(file.Posts) != 3 || file.Posts[0].Post.PostNumber != 2 {
+		t.Fatalf("bad file finding %+v", file)
+	}
+	if !file.Start.Equal(time.Unix(1010, 0)) || !file.End.Equal(time.Unix(1030, 0)) {
+		t.Fatalf("bad file window %v-%v", file.Start, file.End)
+	}
+	if comment, ok := kinds[SpamRepeatedComment]; !ok || len(comment.Posts) != 4 {
+		t.Fatalf("bad comment finding %+v", comment)
+	}
+	if _, ok := kinds[SpamPostRate]; ok {
+		t.Fatal("unexpected rate finding")
+	}
+
+	findings = thread.DetectSpam(&SpamOptions{MaxRate: 0.5, MinPosters: 4, MinRepeats: 4})
+	if len(findings) != 1 || findings[0].Kind != SpamPostRate || len(findings[0].Posts) != 4 {
+		t.Fatalf("bad findings %+v", findings)
+	}
+}
@@ -0,0 +1,109 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Load the OP and the last replies of a thread from its -tail.json.
+// The number of replies included is reported in the OP's TailSize.
+func LoadThreadTail(board, id string) (*Thread, error) {
+	return defaultClient().LoadThreadTail(context.Background(), board, id)
+}
+
+// Load the OP and the last replies of a thread from its -tail.json.
+// The number of replies included is reported in the OP's TailSize.
+func (c *Client) LoadThreadTail(ctx context.Context, board, id string) (*Thread, error) {
+	return c.loadThread(ctx, fmt.Sprintf("%s/%s/thread/%s-tail.json", c.apiURL, board, id), board, id)
+}
+
+// Combine the posts of a thread we already have with a tail of it.
+// The OP is taken from the tail since it carries the current thread state.
+// complete is false when replies between the known posts and the start of
+// the tail were never seen, in which case the full thread has to be fetched.
+// An immutable thread is returned as it is and reported complete, since it will never change and
+// there is nothing to fetch, see Thread.IsImmutable. Use UpdateThreadFromTail to have that refused.
+func (t *Thread) MergeTail(tail *Thread) (merged *Thread, complete bool) {
+	if t.IsImmutable() {
+		return t, true
+	}
+	if len(tail.Posts) == 0 {
+		return t, false
+	}
+	if len(t.Posts) == 0 {
+		return tail, tailIsWholeThread(tail)
+	}
+
+	last := t.lastPostNumber()
+
+	merged = &Thread{
+		Board:      t.Board,
+		Posts:      make([]Post, 0, len(t.Posts)+len(tail.Posts)),
+		FetchedAt:  tail.FetchedAt,
+		ModifiedAt: tail.ModifiedAt,
+	}
+	merged.Posts = append(merged.Posts, tail.Posts[0])
+	merged.Posts = append(merged.Posts, t.Posts[1:]...)
+
+	overlap := false
+	for _, post := range tail.Posts[1:] {
+		if post.PostNumber <= last {
+			overlap = true
+			continue
+		}
+		merged.Posts = append(merged.Posts, post)
+	}
+
+	op := &tail.Posts[0]
+	switch {
+	case overlap:
+		complete = true
+	case op.TailID != 0 && last >= op.TailID:
+		complete = true
+	case tailIsWholeThread(tail):
+		complete = true
+	default:
+		// Everything we know is older than the tail, so anything in between could be missing.
+		// The reply count catches the case where nothing was posted in between.
+		complete = op.ReplyCount > 0 && len(merged.Posts) == op.ReplyCount+1
+	}
+
+	return merged, complete
+}
+
+// Does a tail contain every reply in the thread?
+func tailIsWholeThread(tail *Thread) bool {
+	return len(tail.Posts) > 0 && len(tail.Posts)-1 >= tail.Posts[0].ReplyCount
+}
+
+// Fetch the tail of a thread and merge it into known.
+// Falls back to fetching the full thread when the tail leaves a gap.
+func UpdateThreadFromTail(known *Thread) (*Thread, error) {
+	return defaultClient().UpdateThreadFromTail(context.Background(), known)
+}
+
+// Fetch the tail of a thread and merge it into known.
+// Falls back to fetching the full thread when the tail leaves a gap.
+// Returns ImmutableThreadError for an archived thread rather than fetching it again.
+func (c *Client) UpdateThreadFromTail(ctx context.Context, known *Thread) (*Thread, error) {
+	if len(known.Posts) == 0 {
+		return nil, fmt.Errorf("can't update a thread without posts")
+	}
+	id := strconv.FormatUint(known.Posts[0].PostNumber, 10)
+	if known.IsImmutable() {
+		return nil, ImmutableThreadError{known.Board, id}
+	}
+
+	tail, err := c.LoadThreadTail(ctx, known.Board, id)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, complete := known.MergeTail(tail)
+	if complete {
+		return merged, nil
+	}
+
+	return c.LoadThreadById(ctx, known.Board, id)
+}
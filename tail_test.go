@@ -0,0 +1,101 @@
+package fourchan
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func postNumbers(thread *Thread) []uint64 {
+	var numbers []uint64
+	for _, post := range thread.Posts {
+		numbers = append(numbers, post.PostNumber)
+	}
+	return numbers
+}
+
+func TestMergeTail(t *testing.T) {
+	known := &Thread{Board: "g", Posts: []Post{
+		{Meta: Meta{PostNumber: 1, ReplyCount: 2}},
+		{Meta: Meta{PostNumber: 5}},
+		{Meta: Meta{PostNumber: 9}},
+	}}
+
+	tests := []struct {
+		tail     *Thread
+		numbers  []uint64
+		complete bool
+	}{
+		// Overlaps the known posts.
+		{&Thread{Posts: []Post{{Meta: Meta{PostNumber: 1, ReplyCount: 4}}, {Meta: Meta{PostNumber: 9}}, {Meta: Meta{PostNumber: 12}}}},
+			[]uint64{1, 5, 9, 12}, true},
+		// Starts right after the known posts.
+		{&Thread{Posts: []Post{{Meta: Meta{PostNumber: 1, ReplyCount: 10, TailID: 9}}, {Meta: Meta{PostNumber: 20}}}},
+			[]uint64{1, 5, 9, 20}, true},
+		// Starts well after the known posts.
+		{&Thread{Posts: []Post{{Meta: Meta{PostNumber: 1, ReplyCount: 10, TailID: 15}}, {Meta: Meta{PostNumber: 20}}}},
+			[]uint64{1, 5, 9, 20}, false},
+		// No tail_id but the reply count adds up.
+		{&Thread{Posts: []Post{{Meta: Meta{PostNumber: 1, ReplyCount: 3}}, {Meta: Meta{PostNumber: 20}}}},
+			[]uint64{1, 5, 9, 20}, true},
+	}
+
+	for i, test := range tests {
+		merged, complete := known.MergeTail(test.tail)
+		if complete != test.complete {
+			t.Fatalf("%d: complete %v != %v", i, complete, test.complete)
+		}
+		if fmt.Sprint(postNumbers(merged)) != fmt.Sprint(test.numbers) {
+			t.Fatalf("%d: %v != %v", i, postNumbers(merged), test.numbers)
+		}
+		if merged.Posts[0].ReplyCount != test.tail.Posts[0].ReplyCount {
+			t.Fatalf("%d: OP not taken from tail", i)
+		}
+	}
+
+	archived := &Thread{Board: "g", Archive: "desuarchive.org", Posts: known.Posts}
+	if merged, complete := archived.MergeTail(tests[0].tail); merged != archived || !complete {
+		t.Fatalf("archived thread merged %v", postNumbers(merged))
+	}
+}
+
+func TestUpdateThreadFromTailFallsBack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/g/thread/1-tail.json":
+			fmt.Fprint(w, `{"posts": [{"no": 1, "replies": 3, "tail_size": 1, "tail_id": 7}, {"no": 8}]}`)
+		case "/g/thread/1.json":
+			fmt.Fprint(w, `{"posts": [{"no": 1, "replies": 3}, {"no": 5}, {"no": 7}, {"no": 8}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	known := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1}}, {Meta: Meta{PostNumber: 5}}}}
+	thread, err := UpdateThreadFromTail(known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(postNumbers(thread)) != "[1 5 7 8]" {
+		t.Fatalf("bad posts %v", postNumbers(thread))
+	}
+	if thread.Board != "g" {
+		t.Fatalf("bad board %s", thread.Board)
+	}
+
+	known.Posts[0].Archived = true
+	if _, err = UpdateThreadFromTail(known); err != (ImmutableThreadError{"g", "1"}) {
+		t.Fatalf("archived thread updated: %v", err)
+	}
+	if merged, complete := known.MergeTail(thread); merged != known || !complete {
+		t.Fatal("tail merged into an archived thread")
+	}
+	if kept := known.KeepRolledOff(thread); kept != known {
+		t.Fatal("rolled off replies merged into an archived thread")
+	}
+}
@@ -0,0 +1,130 @@
+package fourchan
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Common English words, for TermOptions.Stopwords.
+var DefaultStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have", "he", "i", "if", "in",
+	"is", "it", "its", "it's", "just", "me", "my", "no", "not", "of", "on", "or", "so", "that", "the", "their",
+	"them", "there", "they", "this", "to", "was", "we", "what", "with", "you", "your",
+}
+
+// Options for counting terms.
+type TermOptions struct {
+	// Count runs of up to this many words as well as single words, defaults to 1.
+	MaxN int
+	// Words to leave out, compared case insensitively. N-grams run across the gaps they leave.
+	Stopwords []string
+	// Leave out terms seen fewer times than this.
+	MinCount int
+}
+
+// How often a word or run of words appears.
+type TermCount struct {
+	// The words, lower cased and separated by single spaces.
+	Term string `json:"term"`
+	// How many words are in Term.
+	N     int `json:"n"`
+	Count int `json:"count"`
+}
+
+// Count the words and n-grams in the comments and subjects of the threads.
+// Markup is stripped and quote links are left out, text is split on anything that isn't a letter,
+// number or apostrophe. Terms are ordered by count, most common first, then alphabetically.
+// The result marshals to JSON as is, WriteTermsCSV writes it as CSV.
+func CountTerms(threads []*Thread, opts *TermOptions) []TermCount {
+	if opts == nil {
+		opts = &TermOptions{}
+	}
+	maxN := opts.MaxN
+	if maxN < 1 {
+		maxN = 1
+	}
+	stop := map[string]bool{}
+	for _, word := range opts.Stopwords {
+		stop[strings.ToLower(word)] = true
+	}
+
+	type key struct {
+		term string
+		n    int
+	}
+	counts := map[key]int{}
+	for _, thread := range threads {
+		for i := range thread.Posts {
+			post := &thread.Posts[i]
+			var words []string
+			for _, word := range termWords(commentText(post.Subject) + "\n" + termText(post.CommentNodes())) {
+				if !stop[word] {
+					words = append(words, word)
+				}
+			}
+			for n := 1; n <= maxN; n++ {
+				for start := 0; start+n <= len(words); start++ {
+					counts[key{strings.Join(words[start:start+n], " "), n}]++
+				}
+			}
+		}
+	}
+
+	terms := []TermCount{}
+	for k, count := range counts {
+		if count >= opts.MinCount {
+			terms = append(terms, TermCount{k.term, k.n, count})
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	return terms
+}
+
+// The text of comment nodes without quote links.
+func termText(nodes []CommentNode) string {
+	var b strings.Builder
+	walkNodes(nodes, func(n *CommentNode) bool {
+		switch n.Kind {
+		case NodeText, NodeCode:
+			b.WriteString(n.Text)
+		case NodeLineBreak:
+			b.WriteByte('\n')
+		}
+		return true
+	})
+	return b.String()
+}
+
+// Split text into lower cased words.
+func termWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+	})
+	kept := words[:0]
+	for _, word := range words {
+		if word = strings.Trim(word, "'"); word != "" {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// Write term counts as CSV with a term,n,count header.
+func WriteTermsCSV(w io.Writer, terms []TermCount) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"term", "n", "count"})
+	for _, term := range terms {
+		cw.Write([]string{term.Term, strconv.Itoa(term.N), strconv.Itoa(term.Count)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
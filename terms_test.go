@@ -0,0 +1,36 @@
+package fourchan
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCountTerms(t *testing.T) {
+	threads := []*Thread{{Posts: []Post{
+		{Subject: "Linux thread", Comment: `Linux is the best kernel`},
+		{Comment: `<a href="#p1" class="quotelink">&gt;&gt;1</a><br><span class="quote">&gt;linux kernel</span> it&#039;s the <b>Linux</b> kernel`},
+	}}}
+
+	terms := CountTerms(threads, &TermOptions{MaxN: 2, Stopwords: DefaultStopwords, MinCount: 2})
+	want := []TermCount{
+		{"linux", 1, 4},
+		{"kernel", 1, 3},
+		{"linux kernel", 2, 2},
+	}
+	if !reflect.DeepEqual(terms, want) {
+		t.Fatalf("got  %+v\nwant %+v", terms, want)
+	}
+
+	if all := CountTerms(threads, nil); len(all) != 7 || all[2] != (TermCount{"the", 1, 2}) || all[5].Term != "it's" {
+		t.Fatalf("bad default counts %+v", all)
+	}
+
+	var b bytes.Buffer
+	if err := WriteTermsCSV(&b, want[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "term,n,count\nlinux,1,4\nkernel,1,3\n" {
+		t.Fatalf("bad CSV %q", b.String())
+	}
+}
@@ -17,14 +17,21 @@ along with this program.  If not, see <http://www.gnu.org/licenses/>.
 */
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"regexp"
-	"strconv"
+	"sort"
+	"time"
 )
 
+// The number of a post, as in Meta.PostNumber.
+// Post numbers count up per board, so a post is only identified by its number and board.
+type PostID = uint64
+
+// The number of a thread, which is the post number of its OP.
+type ThreadID = PostID
+
 // Meta information about a post in a thread.
 // Note that some fields are optional and may contain only their default values.
 // https://github.com/4chan/4chan-API
@@ -55,6 +62,8 @@ type Meta struct {
 	UnixTime uint64 `json:"time"`
 	// unix time last modified
 	LastModified uint64 `json:"last_modified"`
+	// unix time the thread was archived, only on the OP
+	ArchivedOn uint64 `json:"archived_on"`
 	// String based time representation
 	Time string `json:"now"`
 
@@ -62,8 +71,9 @@ type Meta struct {
 	AdminId string `json:"id"`
 	// admin, mod, etc
 	AdminType string `json:"capcode"`
-	// Which comments in the thread are admin replies (I think?)
-	AdminReplies []uint64 `json:"admin"`
+	// Post numbers of capcoded replies in the thread keyed by capcode (admin, mod, developer, ...).
+	// Only present on the OP.
+	CapcodeReplies map[string][]PostID `json:"capcode_replies"`
 
 	// Look at me look at me
 	Name string `json:"name"`
@@ -74,6 +84,8 @@ type Meta struct {
 	CountryCode string `json:"country"`
 	// Poster's country
 	Country string `json:"country_name"`
+	// Board specific flag used on /pol/ instead of a country
+	TrollCountry string `json:"troll_country"`
 
 	// The original filename
 	OrigFileName string `json:"filename"`
@@ -112,6 +124,14 @@ type Meta struct {
 	Tag string `json:"tag"`
 	// Only occurs at the top level of the post
 	SemanticUrl string `json:"semantic_url"`
+
+	// Non zero on rolling stickies, which drop their oldest replies instead of hitting a limit.
+	StickyCap int `json:"sticky_cap"`
+
+	// Number of replies included in a -tail.json response, only on the OP.
+	TailSize int `json:"tail_size"`
+	// Post number just before the replies included in a -tail.json response, only on the OP.
+	TailID uint64 `json:"tail_id"`
 }
 
 // A single post in a thread.
@@ -124,11 +144,18 @@ type Post struct {
 	Comment string `json:"com"`
 
 	// OrigFileName + . + FileExt
+	//
+	// Deprecated: Use FileName, which doesn't depend on how the post was made.
 	FullOrigFileName string
 
 	// str(RenamedFileName) + . + FileExt
+	//
+	// Deprecated: Use NewFileName, which doesn't depend on how the post was made.
 	FullNewFileName string
 
+	// What was odd about the post as decoded, zero for nearly every post.
+	Anomalies PostAnomaly `json:"-"`
+
 	// All of the meta info for this post
 	Meta
 }
@@ -192,6 +219,9 @@ func (p *Post) UnmarshalJSON(data []byte) error {
 		ImageLimitInt  int `json:"imagelimit"`
 		SpoilerInt     int `json:"spoiler"`
 		StickyInt      int `json:"sticky"`
+
+		// Decoded on its own to tell a missing size from 0.
+		FileSize *int `json:"fsize"`
 	}{
 		Alias: (*Alias)(p),
 	}
@@ -209,22 +239,111 @@ func (p *Post) UnmarshalJSON(data []byte) error {
 	p.Spoiler = intToBool(tmp.SpoilerInt)
 	p.Sticky = intToBool(tmp.StickyInt)
 
+	// Still filled in for code reading the fields.
 	p.FullOrigFileName = p.OrigFileName + p.FileExt
 	if p.RenamedFileName != 0 {
-		p.FullNewFileName = strconv.FormatUint(p.RenamedFileName, 10) + p.FileExt
+		p.FullNewFileName = p.NewFileName()
 		p.HasFile = true
 	}
+	if tmp.FileSize != nil {
+		p.FileSize = *tmp.FileSize
+	}
+	p.checkAnomalies(tmp.FileSize)
 
 	return nil
 }
 
 // A thread.
 // We add the board to this to ease the work of interface consumers.
+//
+// Threads are snapshots: everything in this package that updates a thread
+// (MergeTail, UpdateThreadFromTail, Expanded, ThreadWatcher) returns a new
+// Thread and leaves the one it was given alone, so a Thread can be read from
+// any number of goroutines. Expand is the exception, it replaces the thread in
+// place and must not race with readers. Callers changing a Thread themselves
+// have to synchronize that on their own.
 type Thread struct {
 	// The list of comments in this thread.
 	Posts []Post `json:"posts"`
 	// The board this thread is on.
 	Board string
+	// When the thread was fetched.
+	FetchedAt time.Time
+	// When the server says the thread last changed, zero if it didn't say.
+	ModifiedAt time.Time
+	// Name of the archive the thread was loaded from, empty when it came from 4chan itself.
+	Archive string
+}
+
+// A deep copy of the post.
+func (p *Post) Clone() *Post {
+	clone := *p
+	if p.CapcodeReplies != nil {
+		clone.CapcodeReplies = make(map[string][]PostID, len(p.CapcodeReplies))
+		for capcode, replies := range p.CapcodeReplies {
+			clone.CapcodeReplies[capcode] = append([]PostID(nil), replies...)
+		}
+	}
+	return &clone
+}
+
+// A deep copy of the thread, sharing nothing with the original.
+func (t *Thread) Clone() *Thread {
+	clone := *t
+	if t.Posts != nil {
+		clone.Posts = make([]Post, len(t.Posts))
+		for i := range t.Posts {
+			clone.Posts[i] = *t.Posts[i].Clone()
+		}
+	}
+	return &clone
+}
+
+// Up to limit posts starting at offset, in thread order, for serving a huge thread a page at a time.
+// A limit of 0 or less returns every post from offset on. The posts are shared with the thread.
+func (t *Thread) PostRange(offset, limit int) []Post {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(t.Posts) {
+		return nil
+	}
+	end := len(t.Posts)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return t.Posts[offset:end:end]
+}
+
+// Up to limit posts numbered after no, in thread order.
+// Unlike an offset this stays put when earlier posts are deleted, so it suits loading a thread as it is read.
+func (t *Thread) PostsAfter(no PostID, limit int) []Post {
+	offset := sort.Search(len(t.Posts), func(i int) bool { return t.Posts[i].PostNumber > no })
+	return t.PostRange(offset, limit)
+}
+
+// Post numbers of replies in the thread made with the given capcode.
+func (t *Thread) CapcodeReplies(capcode string) []PostID {
+	if len(t.Posts) == 0 {
+		return nil
+	}
+	return t.Posts[0].CapcodeReplies[capcode]
+}
+
+// Post numbers of replies in the thread made by moderators.
+func (t *Thread) ModReplies() []PostID {
+	return t.CapcodeReplies("mod")
+}
+
+// Post numbers of replies in the thread made by admins.
+func (t *Thread) AdminReplies() []PostID {
+	return t.CapcodeReplies("admin")
+}
+
+// Will the thread never change again? Archived threads and threads loaded from a third party archive
+// are read only, updating or watching them is refused with ImmutableThreadError.
+func (t *Thread) IsImmutable() bool {
+	return t.Archive != "" || len(t.Posts) > 0 && t.Posts[0].Archived
 }
 
 // Custom error to indicate we were unable to extract necessary info from the provided URL.
@@ -237,6 +356,28 @@ func (e URLMatchError) Error() string {
 	return fmt.Sprintf("Could not extract thread info from %s", e.url)
 }
 
+// Custom error to indicate a thread doesn't exist, it was pruned, deleted or never existed.
+type ThreadNotFoundError struct {
+	Board string
+	ID    string
+}
+
+// Name the missing thread.
+func (e ThreadNotFoundError) Error() string {
+	return fmt.Sprintf("Thread /%s/%s not found", e.Board, e.ID)
+}
+
+// Custom error to indicate a thread is archived and can't be updated or watched, see Thread.IsImmutable.
+type ImmutableThreadError struct {
+	Board string
+	ID    string
+}
+
+// Name the read only thread.
+func (e ImmutableThreadError) Error() string {
+	return fmt.Sprintf("Thread /%s/%s is archived and can't change", e.Board, e.ID)
+}
+
 // Extract the board and thread ID from a given URL.
 func extractBoardAndThreadId(url string) (board string, id string, err error) {
 	err = nil
@@ -262,34 +403,21 @@ func extractBoardAndThreadId(url string) (board string, id string, err error) {
 
 // Given an URL, extract the board and thread ID then load the thread.
 func LoadThreadFromURL(url string) (*Thread, error) {
-	board, id, err := extractBoardAndThreadId(url)
-	if err != nil {
-		return nil, err
-	}
-
-	return LoadThreadById(board, id)
+	return defaultClient().LoadThreadFromURL(context.Background(), url)
 }
 
 // Load a thread by board and ID.
-func LoadThreadById(board, id string) (*Thread, error) {
-	url := fmt.Sprintf("https://a.4cdn.org/%s/thread/%s.json", board, id)
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	thread := &Thread{}
-	err = json.Unmarshal(bodyBytes, thread)
-	if err != nil {
-		return nil, err
-	}
+func LoadThread(board string, id ThreadID) (*Thread, error) {
+	return defaultClient().LoadThread(context.Background(), board, id)
+}
 
-	thread.Board = board
+// Load a thread by board and ID, as a string.
+func LoadThreadById(board, id string) (*Thread, error) {
+	return defaultClient().LoadThreadById(context.Background(), board, id)
+}
 
-	return thread, nil
+// Has the thread changed since t?
+// This only asks for the headers, so it is much cheaper than loading the thread.
+func ThreadModifiedSince(board, id string, t time.Time) (bool, error) {
+	return defaultClient().ThreadModifiedSince(context.Background(), board, id, t)
 }
@@ -1,7 +1,13 @@
 package fourchan
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestExtractBoardAndThreadId(t *testing.T) {
@@ -31,3 +37,119 @@ func TestExtractBoardAndThreadIdFails(t *testing.T) {
 		t.Fatal("err was nil")
 	}
 }
+
+func TestCapcodeReplies(t *testing.T) {
+	data := `{"posts": [{"no": 1, "capcode_replies": {"admin": [3], "mod": [2, 4]}}, {"no": 2, "capcode": "mod"}]}`
+
+	thread := &Thread{}
+	if err := json.Unmarshal([]byte(data), thread); err != nil {
+		t.Fatal(err)
+	}
+
+	if mod := thread.ModReplies(); len(mod) != 2 || mod[0] != 2 || mod[1] != 4 {
+		t.Fatalf("bad mod replies %v", mod)
+	}
+	if admin := thread.AdminReplies(); len(admin) != 1 || admin[0] != 3 {
+		t.Fatalf("bad admin replies %v", admin)
+	}
+	if dev := thread.CapcodeReplies("developer"); dev != nil {
+		t.Fatalf("bad developer replies %v", dev)
+	}
+}
+
+func TestThreadModifiedSince(t *testing.T) {
+	modified := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/g/thread/1.json" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "1.json", modified, strings.NewReader(`{"posts": [{"no": 1}]}`))
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	changed, err := ThreadModifiedSince("g", "1", modified.Add(-time.Hour))
+	if err != nil || !changed {
+		t.Fatalf("not changed before modification: %v", err)
+	}
+	changed, err = ThreadModifiedSince("g", "1", modified)
+	if err != nil || changed {
+		t.Fatalf("changed after modification: %v", err)
+	}
+	if _, err = ThreadModifiedSince("g", "2", modified); err == nil {
+		t.Fatal("no error for missing thread")
+	}
+
+	thread, err := LoadThreadById("g", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !thread.ModifiedAt.Equal(modified) || thread.FetchedAt.IsZero() {
+		t.Fatalf("bad times %v %v", thread.ModifiedAt, thread.FetchedAt)
+	}
+}
+
+func TestThreadClone(t *testing.T) {
+	thread := &Thread{Board: "g", Posts: []Post{
+		{Comment: "op", Meta: Meta{PostNumber: 1, CapcodeReplies: map[string][]uint64{"mod": {2}}}},
+		{Comment: "reply", Meta: Meta{PostNumber: 2}},
+	}}
+
+	clone := thread.Clone()
+	clone.Posts[1].Comment = "edited"
+	clone.Posts[0].CapcodeReplies["mod"][0] = 3
+	clone.Posts[0].CapcodeReplies["admin"] = []uint64{2}
+	clone.Posts = append(clone.Posts, Post{})
+
+	if thread.Posts[1].Comment != "reply" || len(thread.Posts) != 2 {
+		t.Fatal("posts shared")
+	}
+	if thread.ModReplies()[0] != 2 || thread.AdminReplies() != nil {
+		t.Fatal("capcode replies shared")
+	}
+	if clone.Board != "g" {
+		t.Fatal("board not copied")
+	}
+
+	post := thread.Posts[0].Clone()
+	post.CapcodeReplies["mod"][0] = 4
+	if thread.ModReplies()[0] != 2 {
+		t.Fatal("post capcode replies shared")
+	}
+}
+
+func TestThreadPostRange(t *testing.T) {
+	thread := &Thread{}
+	for _, no := range []uint64{1, 3, 4, 7, 9} {
+		thread.Posts = append(thread.Posts, Post{Meta: Meta{PostNumber: no}})
+	}
+	numbers := func(posts []Post) (ns []uint64) {
+		for _, p := range posts {
+			ns = append(ns, p.PostNumber)
+		}
+		return ns
+	}
+
+	if got := numbers(thread.PostRange(1, 2)); !reflect.DeepEqual(got, []uint64{3, 4}) {
+		t.Fatal(got)
+	}
+	if got := numbers(thread.PostRange(3, 0)); !reflect.DeepEqual(got, []uint64{7, 9}) {
+		t.Fatal(got)
+	}
+	if got := thread.PostRange(5, 10); got != nil {
+		t.Fatal(got)
+	}
+	if got := numbers(thread.PostsAfter(4, 1)); !reflect.DeepEqual(got, []uint64{7}) {
+		t.Fatal(got)
+	}
+	// A deleted post still works as a cursor.
+	if got := numbers(thread.PostsAfter(5, 0)); !reflect.DeepEqual(got, []uint64{7, 9}) {
+		t.Fatal(got)
+	}
+	if got := thread.PostsAfter(9, 10); got != nil {
+		t.Fatal(got)
+	}
+}
@@ -0,0 +1,95 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// A thread as listed in a board's threads.json.
+type ThreadSummary struct {
+	// The post number of the OP.
+	PostNumber uint64 `json:"no"`
+	// unix time the thread last changed
+	LastModified uint64 `json:"last_modified"`
+	// Number of replies in the thread.
+	ReplyCount int `json:"replies"`
+}
+
+// One index page of a board's threads.json.
+type ThreadListPage struct {
+	// The page number, starting at 1.
+	Page int `json:"page"`
+	// The threads on the page in index order.
+	Threads []ThreadSummary `json:"threads"`
+}
+
+// Every live thread on a board, in index order.
+type ThreadList struct {
+	// The index pages.
+	Pages []ThreadListPage
+	// The board the threads are on.
+	Board string
+	// When the list was fetched.
+	FetchedAt time.Time
+}
+
+// Load the list of live threads on a board from threads.json.
+func LoadThreadList(board string) (*ThreadList, error) {
+	return defaultClient().LoadThreadList(context.Background(), board)
+}
+
+// Load the list of live threads on a board from threads.json.
+func (c *Client) LoadThreadList(ctx context.Context, board string) (*ThreadList, error) {
+	list := &ThreadList{Board: board}
+	if _, err := c.getJSON(ctx, fmt.Sprintf("%s/%s/threads.json", c.apiURL, board), &list.Pages); err != nil {
+		return nil, err
+	}
+	list.FetchedAt = time.Now()
+
+	return list, nil
+}
+
+// Find where a thread sits in the list.
+// Returns false if the thread isn't live, it was pruned, archived or deleted.
+func (l *ThreadList) Position(id uint64) (BoardPosition, bool) {
+	perPage := 0
+	for _, page := range l.Pages {
+		if len(page.Threads) > perPage {
+			perPage = len(page.Threads)
+		}
+	}
+
+	for _, page := range l.Pages {
+		for i, thread := range page.Threads {
+			if thread.PostNumber == id {
+				return BoardPosition{Page: page.Page, Index: i, Pages: len(l.Pages), PerPage: perPage}, true
+			}
+		}
+	}
+	return BoardPosition{}, false
+}
+
+// Look up which index page a thread is on.
+// Returns false if the thread is no longer live on the board.
+func ThreadPage(board, id string) (BoardPosition, bool, error) {
+	return defaultClient().ThreadPage(context.Background(), board, id)
+}
+
+// Look up which index page a thread is on.
+// Returns false if the thread is no longer live on the board.
+func (c *Client) ThreadPage(ctx context.Context, board, id string) (BoardPosition, bool, error) {
+	no, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return BoardPosition{}, false, err
+	}
+
+	list, err := c.LoadThreadList(ctx, board)
+	if err != nil {
+		return BoardPosition{}, false, err
+	}
+
+	pos, ok := list.Position(no)
+	return pos, ok, nil
+}
@@ -0,0 +1,40 @@
+package fourchan
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestThreadPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/g/threads.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"page": 1, "threads": [{"no": 10, "last_modified": 100, "replies": 3}, {"no": 11, "last_modified": 99, "replies": 0}]},
+			{"page": 2, "threads": [{"no": 12, "last_modified": 98, "replies": 1}]}
+		]`)
+	}))
+	defer server.Close()
+
+	defer func(c *Client) { DefaultClient = c }(DefaultClient)
+	DefaultClient = newTestClient(server)
+
+	pos, ok, err := ThreadPage("g", "12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || pos != (BoardPosition{Page: 2, Index: 0, Pages: 2, PerPage: 2}) {
+		t.Fatalf("bad position %+v %v", pos, ok)
+	}
+	if pos.Rank() != 2 {
+		t.Fatalf("%d != 2", pos.Rank())
+	}
+
+	if _, ok, err = ThreadPage("g", "13"); err != nil || ok {
+		t.Fatalf("found a missing thread: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Thumbnails for archived media, for when the site's own weren't saved.
+package thumbnail
+
+/*
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+import (
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// The longest side of the thumbnails 4chan makes for OPs and replies.
+const (
+	OPSize    = 250
+	ReplySize = 125
+)
+
+// Make a JPEG thumbnail of the image in r that fits in size by size, like the site's own.
+// JPEG, PNG and GIF files can be read, the first frame of a GIF is used. Anything else,
+// such as WebM, fails with image.ErrFormat. Images already small enough aren't scaled up.
+func Generate(w io.Writer, r io.Reader, size int) error {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return err
+	}
+	return jpeg.Encode(w, Scale(src, size), &jpeg.Options{Quality: 85})
+}
+
+// Shrink img to fit in size by size, keeping its aspect ratio.
+// Each pixel is the average of the pixels it covers, and transparency is flattened onto white.
+func Scale(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > size || h > size {
+		if w >= h {
+			w, h = size, max(1, h*size/b.Dx())
+		} else {
+			w, h = max(1, w*size/b.Dy()), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+			dst.Set(x, y, average(img, x0, y0, max(x1, x0+1), max(y1, y0+1)))
+		}
+	}
+	return dst
+}
+
+// The average color of a block of pixels, over white.
+func average(img image.Image, x0, y0, x1, y1 int) color.Color {
+	var r, g, b, n uint64
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			pr, pg, pb, pa := img.At(x, y).RGBA()
+			// Colors are premultiplied, so adding what's left of white flattens them onto it.
+			white := 0xffff - pa
+			r += uint64(pr + white)
+			g += uint64(pg + white)
+			b += uint64(pb + white)
+			n++
+		}
+	}
+	return color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), 0xffff}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,55 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+	for y := 0; y < 500; y++ {
+		for x := 0; x < 1000; x++ {
+			if x < 500 {
+				src.Set(x, y, color.RGBA{255, 0, 0, 255})
+			}
+			// The right half is transparent, which becomes white.
+		}
+	}
+	var in bytes.Buffer
+	png.Encode(&in, src)
+
+	var out bytes.Buffer
+	if err := Generate(&out, &in, OPSize); err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := jpeg.Decode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 250 || b.Dy() != 125 {
+		t.Fatalf("bad size %v", b)
+	}
+	if r, g, _, _ := thumb.At(10, 10).RGBA(); r < 0xe000 || g > 0x2000 {
+		t.Fatalf("left isn't red: %v", thumb.At(10, 10))
+	}
+	if r, g, b, _ := thumb.At(240, 10).RGBA(); r < 0xe000 || g < 0xe000 || b < 0xe000 {
+		t.Fatalf("right isn't white: %v", thumb.At(240, 10))
+	}
+
+	if err = Generate(&out, bytes.NewReader([]byte("\x1aE\xdf\xa3 webm")), ReplySize); err != image.ErrFormat {
+		t.Fatalf("expected a format error, got %v", err)
+	}
+}
+
+func TestScaleSmall(t *testing.T) {
+	if b := Scale(image.NewRGBA(image.Rect(0, 0, 40, 100)), ReplySize).Bounds(); b.Dx() != 40 || b.Dy() != 100 {
+		t.Fatalf("small image scaled to %v", b)
+	}
+	if b := Scale(image.NewRGBA(image.Rect(0, 0, 10, 5000)), ReplySize).Bounds(); b.Dx() != 1 || b.Dy() != 125 {
+		t.Fatalf("tall image scaled to %v", b)
+	}
+}
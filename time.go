@@ -0,0 +1,75 @@
+package fourchan
+
+import (
+	"time"
+)
+
+// The layout the site uses for the "now" field, e.g. 12/25/15(Fri)13:37:00.
+const SiteTimeLayout = "01/02/06(Mon)15:04:05"
+
+// The time the post was made.
+func (m *Meta) PostTime() time.Time {
+	return time.Unix(int64(m.UnixTime), 0)
+}
+
+// The time the post was made in the given location.
+func (m *Meta) TimeIn(loc *time.Location) time.Time {
+	return m.PostTime().In(loc)
+}
+
+// Format the post time in the given location the same way the site does.
+func (m *Meta) FormatTime(loc *time.Location) string {
+	return FormatSiteTime(m.TimeIn(loc))
+}
+
+// Format a time the same way the site does.
+func FormatSiteTime(t time.Time) string {
+	return t.Format(SiteTimeLayout)
+}
+
+// How long ago the post was made, relative to now.
+func (m *Meta) Age(now time.Time) time.Duration {
+	return now.Sub(m.PostTime())
+}
+
+// How long ago the thread was started.
+// Returns 0 for a thread without posts.
+func (t *Thread) Age() time.Duration {
+	return t.ageAt(time.Now())
+}
+
+func (t *Thread) ageAt(now time.Time) time.Duration {
+	if len(t.Posts) == 0 {
+		return 0
+	}
+	return t.Posts[0].Age(now)
+}
+
+// The time of the most recent post in the thread.
+// Returns the zero time for a thread without posts.
+func (t *Thread) LastPostTime() time.Time {
+	var last uint64
+	for i := range t.Posts {
+		if t.Posts[i].UnixTime > last {
+			last = t.Posts[i].UnixTime
+		}
+	}
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(last), 0)
+}
+
+// How long ago the last post in the thread was made.
+// Returns 0 for a thread without posts.
+func (t *Thread) TimeSinceLastPost() time.Duration {
+	return t.timeSinceLastPostAt(time.Now())
+}
+
+func (t *Thread) timeSinceLastPostAt(now time.Time) time.Duration {
+	last := t.LastPostTime()
+	if last.IsZero() {
+		return 0
+	}
+	return now.Sub(last)
+}
@@ -0,0 +1,43 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatTime(t *testing.T) {
+	// The site shows times in America/New_York, use a fixed zone so the test doesn't need tzdata.
+	est := time.FixedZone("EST", -5*60*60)
+	m := Meta{UnixTime: 1451068620, Time: "12/25/15(Fri)13:37:00"}
+
+	if formatted := m.FormatTime(est); formatted != m.Time {
+		t.Fatalf("%s != %s", formatted, m.Time)
+	}
+	if formatted := m.FormatTime(time.UTC); formatted != "12/25/15(Fri)18:37:00" {
+		t.Fatalf("%s != 12/25/15(Fri)18:37:00", formatted)
+	}
+}
+
+func TestThreadAge(t *testing.T) {
+	thread := &Thread{Posts: []Post{
+		{Meta: Meta{UnixTime: 1000}},
+		{Meta: Meta{UnixTime: 1600}},
+		{Meta: Meta{UnixTime: 1300}},
+	}}
+	now := time.Unix(2000, 0)
+
+	if age := thread.ageAt(now); age != 1000*time.Second {
+		t.Fatalf("%v != 1000s", age)
+	}
+	if since := thread.timeSinceLastPostAt(now); since != 400*time.Second {
+		t.Fatalf("%v != 400s", since)
+	}
+	if age := thread.Posts[2].Age(now); age != 700*time.Second {
+		t.Fatalf("%v != 700s", age)
+	}
+
+	empty := &Thread{}
+	if empty.ageAt(now) != 0 || empty.timeSinceLastPostAt(now) != 0 {
+		t.Fatal("empty thread has an age")
+	}
+}
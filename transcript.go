@@ -0,0 +1,60 @@
+package fourchan
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// The layout of post times in transcripts, always in UTC.
+const transcriptTimeLayout = "2006-01-02 15:04:05 UTC"
+
+// Write threads as a plain text transcript, for tools that want neither HTML nor Markdown.
+// Each thread starts with a line naming it, e.g. "/g/123: subject", followed by one block per post
+// separated by blank lines. A block is a header line with the post number, name and time,
+// e.g. "No.124 Anonymous 2016-01-02 03:04:05 UTC", a "File: desk.jpg" line for posts with a file,
+// then the comment as plain text.
+// Lines of the comment that are empty are left out, so blank lines only ever separate blocks.
+func WriteTranscript(w io.Writer, threads ...*Thread) error {
+	bw := bufio.NewWriter(w)
+	first := true
+	for _, thread := range threads {
+		if len(thread.Posts) == 0 {
+			continue
+		}
+		if !first {
+			bw.WriteString("\n")
+		}
+		first = false
+
+		title := thread.Ref().String()
+		if subject := html.UnescapeString(thread.Posts[0].Subject); subject != "" {
+			title += ": " + subject
+		}
+		fmt.Fprintf(bw, "%s\n", title)
+		for i := range thread.Posts {
+			bw.WriteString("\n")
+			writeTranscriptPost(bw, &thread.Posts[i])
+		}
+	}
+	return bw.Flush()
+}
+
+// Write one post's block.
+func writeTranscriptPost(w *bufio.Writer, p *Post) {
+	name := html.UnescapeString(p.Name)
+	if name == "" {
+		name = "Anonymous"
+	}
+	fmt.Fprintf(w, "No.%d %s%s %s\n", p.PostNumber, name, p.TripCode, p.PostTime().UTC().Format(transcriptTimeLayout))
+	if file := p.FileName(); file != "" {
+		fmt.Fprintf(w, "File: %s\n", file)
+	}
+	for _, line := range strings.Split(p.PlainText(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			fmt.Fprintf(w, "%s\n", line)
+		}
+	}
+}
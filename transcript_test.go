@@ -0,0 +1,39 @@
+package fourchan
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTranscript(t *testing.T) {
+	thread := &Thread{
+		Board: "g",
+		Posts: []Post{
+			{Subject: "/dpt/ &amp; friends", Comment: "What are you working on?", Meta: Meta{PostNumber: 10, UnixTime: 1451703845, OrigFileName: "desk", FileExt: ".jpg"}},
+			{Comment: `<a href="#p10" class="quotelink">&gt;&gt;10</a><br><br>A <b>compiler</b>`, Meta: Meta{PostNumber: 11, UnixTime: 1451703905, Name: "Anon", TripCode: "!3GqYIJ3Obs"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTranscript(&buf, thread, &Thread{Board: "empty"}, &Thread{Board: "v", Posts: []Post{{Meta: Meta{PostNumber: 5}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `/g/10: /dpt/ & friends
+
+No.10 Anonymous 2016-01-02 03:04:05 UTC
+File: desk.jpg
+What are you working on?
+
+No.11 Anon!3GqYIJ3Obs 2016-01-02 03:05:05 UTC
+>>10
+A compiler
+
+/v/5
+
+No.5 Anonymous 1970-01-01 00:00:00 UTC
+`
+	if buf.String() != want {
+		t.Fatalf("bad transcript:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,138 @@
+package fourchan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// What is trending.
+type TrendKind int
+
+const (
+	// A thread is getting replies quickly.
+	TrendThread TrendKind = iota
+	// Many new threads share a term.
+	TrendTerm
+)
+
+// Name the kind of trend.
+func (k TrendKind) String() string {
+	switch k {
+	case TrendThread:
+		return "thread"
+	case TrendTerm:
+		return "term"
+	}
+	return fmt.Sprintf("TrendKind(%d)", int(k))
+}
+
+// Options for detecting trends.
+type TrendOptions struct {
+	// A thread trends when it gets at least this many replies per hour, defaults to 60.
+	RepliesPerHour float64
+	// A term trends when at least this many new threads have it in their subject or comment, defaults to 3.
+	NewThreads int
+	// Words that never trend, defaults to DefaultStopwords.
+	Stopwords []string
+}
+
+// Something trending on a board between two catalogs.
+type Trend struct {
+	Kind TrendKind
+	// The thread for TrendThread.
+	Thread ThreadRef
+	// The thread's subject, or the start of its comment when it has none.
+	Subject string
+	// How many replies the thread got between the catalogs.
+	Replies int
+	// The rate of those replies.
+	RepliesPerHour float64
+	// The word for TrendTerm.
+	Term string
+	// The new threads with the term, in catalog order.
+	Threads []ThreadRef
+}
+
+// Compare two catalogs of a board, taken some time apart, for threads getting replies quickly and words
+// that many new threads share. Threads that are new in current count their replies from when they were made.
+// Thread trends come first, fastest first, then terms, the most common first.
+func DetectTrends(previous, current *Catalog, opts *TrendOptions) []Trend {
+	if opts == nil {
+		opts = &TrendOptions{}
+	}
+	minRate := opts.RepliesPerHour
+	if minRate <= 0 {
+		minRate = 60
+	}
+	minThreads := opts.NewThreads
+	if minThreads <= 0 {
+		minThreads = 3
+	}
+	stopwords := opts.Stopwords
+	if stopwords == nil {
+		stopwords = DefaultStopwords
+	}
+	stop := map[string]bool{}
+	for _, word := range stopwords {
+		stop[strings.ToLower(word)] = true
+	}
+
+	var threads []Trend
+	terms := map[string][]ThreadRef{}
+	for _, page := range current.Pages {
+		for i := range page.Threads {
+			op := &page.Threads[i].Post
+			ref := ThreadRef{current.Board, op.PostNumber}
+
+			since, replies := previous.FetchedAt, op.ReplyCount
+			if known := previous.Thread(op.PostNumber); known != nil {
+				replies -= known.ReplyCount
+			} else {
+				if op.PostTime().After(since) {
+					since = op.PostTime()
+				}
+				seen := map[string]bool{}
+				for _, word := range termWords(commentText(op.Subject) + "\n" + termText(op.CommentNodes())) {
+					if !stop[word] && !seen[word] {
+						seen[word] = true
+						terms[word] = append(terms[word], ref)
+					}
+				}
+			}
+
+			hours := current.FetchedAt.Sub(since).Hours()
+			if hours < time.Minute.Hours() {
+				hours = time.Minute.Hours()
+			}
+			if rate := float64(replies) / hours; replies > 0 && rate >= minRate {
+				threads = append(threads, Trend{Kind: TrendThread, Thread: ref, Subject: trendSubject(op), Replies: replies, RepliesPerHour: rate})
+			}
+		}
+	}
+	sort.SliceStable(threads, func(i, j int) bool { return threads[i].RepliesPerHour > threads[j].RepliesPerHour })
+
+	var words []Trend
+	for term, refs := range terms {
+		if len(refs) >= minThreads {
+			words = append(words, Trend{Kind: TrendTerm, Term: term, Threads: refs})
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if len(words[i].Threads) != len(words[j].Threads) {
+			return len(words[i].Threads) > len(words[j].Threads)
+		}
+		return words[i].Term < words[j].Term
+	})
+
+	return append(threads, words...)
+}
+
+// What to call a thread in a trend.
+func trendSubject(op *Post) string {
+	if op.Subject != "" {
+		return commentText(op.Subject)
+	}
+	return op.Excerpt(50, true)
+}
@@ -0,0 +1,52 @@
+package fourchan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectTrends(t *testing.T) {
+	start := time.Date(2016, 1, 2, 12, 0, 0, 0, time.UTC)
+	thread := func(no uint64, made time.Time, replies int, subject, comment string) CatalogThread {
+		return CatalogThread{Post: Post{Subject: subject, Comment: comment, Meta: Meta{PostNumber: no, UnixTime: uint64(made.Unix()), ReplyCount: replies}}}
+	}
+	previous := &Catalog{Board: "g", FetchedAt: start, Pages: []CatalogPage{{Page: 1, Threads: []CatalogThread{
+		thread(1, start.Add(-time.Hour), 10, "slow", ""),
+		thread(2, start.Add(-time.Hour), 10, "fast", ""),
+	}}}}
+	current := &Catalog{Board: "g", FetchedAt: start.Add(30 * time.Minute), Pages: []CatalogPage{{Page: 1, Threads: []CatalogThread{
+		thread(1, start.Add(-time.Hour), 15, "slow", ""),
+		thread(2, start.Add(-time.Hour), 60, "fast", ""),
+		thread(3, start.Add(20*time.Minute), 20, "", "The new phone is out"),
+		thread(4, start.Add(25*time.Minute), 0, "Phone general", "which phone"),
+		thread(5, start.Add(25*time.Minute), 0, "", "is the <b>phone</b> worth it"),
+	}}}}
+
+	trends := DetectTrends(previous, current, nil)
+	if len(trends) != 3 {
+		t.Fatalf("expected 3 trends, got %+v", trends)
+	}
+	if trends[0].Kind != TrendThread || trends[0].Thread != (ThreadRef{"g", 3}) || trends[0].Replies != 20 || trends[0].RepliesPerHour != 120 {
+		t.Fatalf("bad first trend %+v", trends[0])
+	}
+	if trends[0].Subject != "The new phone is out" {
+		t.Fatalf("bad subject %q", trends[0].Subject)
+	}
+	if trends[1].Thread != (ThreadRef{"g", 2}) || trends[1].Replies != 50 || trends[1].RepliesPerHour != 100 || trends[1].Subject != "fast" {
+		t.Fatalf("bad second trend %+v", trends[1])
+	}
+	term := trends[2]
+	if term.Kind != TrendTerm || term.Term != "phone" || len(term.Threads) != 3 || term.Threads[0] != (ThreadRef{"g", 3}) {
+		t.Fatalf("bad term trend %+v", term)
+	}
+
+	if trends = DetectTrends(previous, current, &TrendOptions{RepliesPerHour: 200, NewThreads: 4}); len(trends) != 0 {
+		t.Fatalf("expected no trends, got %+v", trends)
+	}
+}
+
+func TestTrendKindString(t *testing.T) {
+	if TrendTerm.String() != "term" || TrendKind(9).String() != "TrendKind(9)" {
+		t.Fatal("bad trend kind names")
+	}
+}
@@ -0,0 +1,70 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestTripcode(t *testing.T) {
+	tests := []struct {
+		password, trip string
+	}{
+		{"tripcode", "!3GqYIJ3Obs"},
+		{"a", "!ZnBI2EKkq."},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if trip := Tripcode(test.password); trip != test.trip {
+			t.Fatalf("%q: %s != %s", test.password, trip, test.trip)
+		}
+	}
+}
+
+func TestDesCrypt(t *testing.T) {
+	// Reference values from the system crypt(3).
+	tests := []struct {
+		key, salt, hash string
+	}{
+		{"tripcode", "ri", "riA3GqYIJ3Obs"},
+		{"tripcode", "H.", "H.Aj7bKPY3SqU"},
+	}
+
+	for _, test := range tests {
+		if hash := desCrypt(test.key, test.salt); hash != test.hash {
+			t.Fatalf("%q/%q: %s != %s", test.key, test.salt, hash, test.hash)
+		}
+	}
+}
+
+func TestTripcodeKind(t *testing.T) {
+	tests := []struct {
+		trip            string
+		kind            TripcodeKind
+		classic, secure string
+	}{
+		{"", TripcodeNone, "", ""},
+		{"!3GqYIJ3Obs", TripcodeClassic, "!3GqYIJ3Obs", ""},
+		{"!!H8BPnz0ldrV", TripcodeSecure, "", "!!H8BPnz0ldrV"},
+		{"!3GqYIJ3Obs!!H8BPnz0ldrV", TripcodeClassicAndSecure, "!3GqYIJ3Obs", "!!H8BPnz0ldrV"},
+	}
+
+	for _, test := range tests {
+		m := Meta{TripCode: test.trip}
+		if kind := m.TripcodeKind(); kind != test.kind {
+			t.Fatalf("%q: %v != %v", test.trip, kind, test.kind)
+		}
+		classic, secure := ParseTripcode(test.trip)
+		if classic != test.classic || secure != test.secure {
+			t.Fatalf("%q: %q/%q != %q/%q", test.trip, classic, secure, test.classic, test.secure)
+		}
+	}
+
+	m := Meta{TripCode: "!3GqYIJ3Obs!!H8BPnz0ldrV"}
+	if !m.MatchesTripcode("tripcode") || m.MatchesTripcode("a") {
+		t.Fatal("bad tripcode match")
+	}
+	m = Meta{TripCode: "!!H8BPnz0ldrV"}
+	if m.MatchesTripcode("") {
+		t.Fatal("secure only trip matched")
+	}
+}
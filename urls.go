@@ -0,0 +1,82 @@
+package fourchan
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Where media is served from.
+var mediaURL = "https://i.4cdn.org"
+
+// Where the boards themselves are served from.
+var boardsURL = "https://boards.4chan.org"
+
+// The name the post's file was posted with, e.g. desk.jpg, empty for posts without one.
+func (p *Post) FileName() string {
+	if p.OrigFileName == "" {
+		return ""
+	}
+	return p.OrigFileName + p.FileExt
+}
+
+// The name the media host gives the post's file, e.g. 1456789012345.jpg, empty for posts without a file.
+// /f/ serves its files under FileName instead, see BoardQuirks.
+func (p *Post) NewFileName() string {
+	if p.RenamedFileName == 0 {
+		return ""
+	}
+	return p.mediaName()
+}
+
+// The name the media host serves a post's file under, e.g. 1456789012345.jpg.
+func (p *Post) mediaName() string {
+	return strconv.FormatUint(p.RenamedFileName, 10) + p.FileExt
+}
+
+// The name the media host serves a post's thumbnail under, e.g. 1456789012345s.jpg.
+func (p *Post) thumbnailName() string {
+	return strconv.FormatUint(p.RenamedFileName, 10) + "s.jpg"
+}
+
+// The name the media host serves a post's file under on the given board, escaped for a URL.
+// That's the original name on boards with BoardQuirks.OriginalFileNames.
+func (p *Post) hostedName(board string) string {
+	if QuirksFor(board).OriginalFileNames && p.OrigFileName != "" {
+		return url.PathEscape(p.OrigFileName + p.FileExt)
+	}
+	return p.mediaName()
+}
+
+// The URL of the post's file on the given board, on DefaultClient's media host.
+func (p *Post) ImageURL(board string) string {
+	return defaultClient().ImageURL(board, p)
+}
+
+// The URL of the post's file on the given board, on the media host the client downloads from, see WithMediaURL.
+func (c *Client) ImageURL(board string, p *Post) string {
+	return fmt.Sprintf("%s/%s/%s", c.mediaURL, board, p.hostedName(board))
+}
+
+// The URL of the thumbnail for the post's file on the given board, on DefaultClient's media host.
+// Empty on boards without thumbnails.
+func (p *Post) ThumbnailURL(board string) string {
+	return defaultClient().ThumbnailURL(board, p)
+}
+
+// The URL of the thumbnail for the post's file on the given board, on the media host the client downloads from.
+// Empty on boards without thumbnails.
+func (c *Client) ThumbnailURL(board string, p *Post) string {
+	if QuirksFor(board).NoThumbnails {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", c.mediaURL, board, p.thumbnailName())
+}
+
+// Build the URL of a post on the site.
+func postURL(board string, thread, post uint64) string {
+	if thread == post {
+		return fmt.Sprintf("%s/%s/thread/%d", boardsURL, board, thread)
+	}
+	return fmt.Sprintf("%s/%s/thread/%d#p%d", boardsURL, board, thread, post)
+}
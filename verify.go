@@ -0,0 +1,83 @@
+package fourchan
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// Custom error to indicate a file's dimensions don't match what the API reported.
+type DimensionMismatchError struct {
+	// What the API said
+	Width, Height int
+	// What the file header says
+	ActualWidth, ActualHeight int
+}
+
+// Pretty print dat error yo.
+func (e DimensionMismatchError) Error() string {
+	return fmt.Sprintf("File is %dx%d but the post says %dx%d", e.ActualWidth, e.ActualHeight, e.Width, e.Height)
+}
+
+// Check the image header read from r against the width and height of the post's file.
+// Only the header is read. Returns DimensionMismatchError when they differ, and
+// image.ErrFormat for files that aren't jpeg, png or gif (webm, pdf, ...).
+func (p *Post) VerifyDimensions(r io.Reader) error {
+	config, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return err
+	}
+
+	if config.Width != p.FileWidth || config.Height != p.FileHeight {
+		return DimensionMismatchError{p.FileWidth, p.FileHeight, config.Width, config.Height}
+	}
+
+	return nil
+}
+
+// Custom error to indicate a file's contents don't match the MD5 the API reported.
+type ChecksumMismatchError struct {
+	// What the API said, base64 encoded
+	Expected string
+	// What the file hashes to, base64 encoded
+	Actual string
+}
+
+// Say which checksums differ.
+func (e ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("File has MD5 %s but the post says %s", e.Actual, e.Expected)
+}
+
+// The MD5 of the post's file in hex, as md5sum prints it. The API sends it base64 encoded, as in FileMD5.
+// Empty when the post has no MD5 or it isn't valid.
+func (p *Post) MD5Hex() string {
+	sum, err := base64.StdEncoding.DecodeString(p.FileMD5)
+	if err != nil || len(sum) != md5.Size {
+		return ""
+	}
+	return hex.EncodeToString(sum)
+}
+
+// Read all of r and check it against the MD5 of the post's file.
+// Returns ChecksumMismatchError when they differ.
+func (p *Post) VerifyMD5(r io.Reader) error {
+	h := md5.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	return p.checkMD5(h.Sum(nil))
+}
+
+// Compare a digest of the post's file with the one the API reported.
+func (p *Post) checkMD5(sum []byte) error {
+	if actual := base64.StdEncoding.EncodeToString(sum); actual != p.FileMD5 {
+		return ChecksumMismatchError{p.FileMD5, actual}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package fourchan
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestVerifyDimensions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+
+	good := &Post{Meta: Meta{FileWidth: 4, FileHeight: 3}}
+	if err := good.VerifyDimensions(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := &Post{Meta: Meta{FileWidth: 3, FileHeight: 4}}
+	err := bad.VerifyDimensions(bytes.NewReader(buf.Bytes()))
+	if _, ok := err.(DimensionMismatchError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = good.VerifyDimensions(bytes.NewReader([]byte("\x1a\x45\xdf\xa3"))); err != image.ErrFormat {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestVerifyMD5(t *testing.T) {
+	// Base64 of the MD5 of "hello", as the API reports it.
+	post := &Post{Meta: Meta{FileMD5: "XUFAKrxLKna5cZ2REBfFkg=="}}
+	if err := post.VerifyMD5(bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatal(err)
+	}
+	err := post.VerifyMD5(bytes.NewReader([]byte("hello!")))
+	if mismatch, ok := err.(ChecksumMismatchError); !ok || mismatch.Expected != post.FileMD5 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if hex := post.MD5Hex(); hex != "5d41402abc4b2a76b9719d911017c592" {
+		t.Fatalf("bad hex %s", hex)
+	}
+	if hex := (&Post{Meta: Meta{FileMD5: "nope"}}).MD5Hex(); hex != "" {
+		t.Fatalf("bad hex for an invalid MD5 %s", hex)
+	}
+}
@@ -0,0 +1,84 @@
+package fourchan
+
+import (
+	"strings"
+)
+
+// The version of the package's API.
+// The minor version goes up when exported identifiers are added, the major version when any are removed.
+// Deprecated identifiers stay until the next major version, see Deprecations.
+const apiVersion = "1.6.0"
+
+// The version of the package's API, e.g. "1.0.0", for tools that link against different versions of it.
+func APIVersion() string {
+	return apiVersion
+}
+
+// A feature of the package tools can check for at runtime, a set of flags.
+type Capability int
+
+const (
+	// Threads gone from 4chan can be loaded from third party archives, see WithArchiveFallback.
+	HasArchiver Capability = 1 << iota
+	// Threads can be polled for changes, see ThreadWatcher.
+	HasWatcher
+	// Media feeds can be built from threads, see BuildMediaFeed.
+	HasFeeds
+	// Threads can be written out as EPUB books, see WriteEPUB.
+	HasEPUB
+	// Posts can be submitted to 4chan. Drafts can only be checked so far, so this isn't set.
+	HasPosting
+	// Boards can be watched for new posts with handlers, see Bot.
+	HasBot
+)
+
+// The features this version of the package has.
+func Capabilities() Capability {
+	return HasArchiver | HasWatcher | HasFeeds | HasEPUB | HasBot
+}
+
+// Does this version of the package have every feature in c?
+func Supports(c Capability) bool {
+	return Capabilities()&c == c
+}
+
+// Name the capabilities, e.g. "archiver, watcher".
+func (c Capability) String() string {
+	var names []string
+	for _, flag := range []struct {
+		capability Capability
+		name       string
+	}{
+		{HasArchiver, "archiver"},
+		{HasWatcher, "watcher"},
+		{HasFeeds, "feeds"},
+		{HasEPUB, "epub"},
+		{HasPosting, "posting"},
+		{HasBot, "bot"},
+	} {
+		if c&flag.capability != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// An exported identifier that is going away.
+type Deprecation struct {
+	// The deprecated identifier, e.g. "Post.FullOrigFileName".
+	Name string
+	// What to use instead.
+	Replacement string
+}
+
+// The exported identifiers that are deprecated in this version of the package.
+// Each is also marked "Deprecated:" in its doc comment, so linters flag uses of it.
+func Deprecations() []Deprecation {
+	return []Deprecation{
+		{"Post.FullOrigFileName", "Post.FileName"},
+		{"Post.FullNewFileName", "Post.NewFileName"},
+	}
+}
@@ -0,0 +1,22 @@
+package fourchan
+
+import (
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	if APIVersion() == "" {
+		t.Fatal("no API version")
+	}
+	if !Supports(HasArchiver|HasWatcher) || Supports(HasPosting) || Supports(HasWatcher|HasPosting) {
+		t.Fatalf("bad capabilities %v", Capabilities())
+	}
+	if (HasArchiver|HasEPUB).String() != "archiver, epub" || Capability(0).String() != "none" {
+		t.Fatalf("bad description %q", HasArchiver|HasEPUB)
+	}
+	for _, d := range Deprecations() {
+		if d.Name == "" || d.Replacement == "" {
+			t.Fatalf("incomplete deprecation %+v", d)
+		}
+	}
+}
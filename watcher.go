@@ -0,0 +1,332 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The API rules ask for threads to be polled no more than every 10 seconds.
+const DefaultWatchInterval = 10 * time.Second
+
+// What happened to a watched thread.
+type WatchEventKind int
+
+const (
+	// Replies were posted, or the thread was seen for the first time.
+	WatchNewPosts WatchEventKind = iota
+	// Posts were deleted.
+	WatchDeletedPosts
+	// The thread was archived, it won't change again.
+	WatchArchived
+	// The thread is gone, it was pruned or deleted.
+	WatchNotFound
+	// A poll failed, the watcher keeps trying unless its RetryPolicy says to give up.
+	WatchError
+	// Polls kept failing and the watcher gave up, Err is the last failure.
+	WatchGaveUp
+	// A rolling sticky dropped its oldest replies to make room for new ones.
+	// These weren't deleted by a moderator, see Thread.KeepRolledOff to hold on to them.
+	WatchRolledOff
+)
+
+// Name the kind of event.
+func (k WatchEventKind) String() string {
+	switch k {
+	case WatchNewPosts:
+		return "new posts"
+	case WatchDeletedPosts:
+		return "deleted posts"
+	case WatchArchived:
+		return "archived"
+	case WatchNotFound:
+		return "not found"
+	case WatchError:
+		return "error"
+	case WatchGaveUp:
+		return "gave up"
+	case WatchRolledOff:
+		return "rolled off"
+	}
+	return fmt.Sprintf("WatchEventKind(%d)", int(k))
+}
+
+// Something that happened to a watched thread.
+type WatchEvent struct {
+	Kind  WatchEventKind
+	Board string
+	// The post number of the OP.
+	Thread ThreadID
+	// The posts that were added, deleted or rolled off.
+	Posts []Post
+	// The thread as of the poll that produced the event, nil for WatchNotFound and WatchError.
+	// Each poll produces a new Thread, so it is safe to keep.
+	Snapshot *Thread
+	// Why the poll failed for WatchError and WatchGaveUp, ThreadNotFoundError for WatchNotFound.
+	Err error
+}
+
+// How a watcher handles polls that fail.
+type RetryPolicy struct {
+	// The delay after the first failure, doubled for each failure in a row after it.
+	// Zero keeps polling at the watcher's Interval.
+	Backoff time.Duration
+	// The longest delay between failed polls, zero for no limit.
+	MaxBackoff time.Duration
+	// Give up after this many failures in a row, zero to never give up.
+	MaxFailures int
+}
+
+// How long to wait after the given number of failures in a row.
+func (p *RetryPolicy) delay(failures int, interval time.Duration) time.Duration {
+	if p == nil || p.Backoff <= 0 || failures == 0 {
+		return interval
+	}
+	delay := p.Backoff
+	for i := 1; i < failures && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// Has the watcher failed too often to go on?
+func (p *RetryPolicy) exhausted(failures int) bool {
+	return p != nil && p.MaxFailures > 0 && failures >= p.MaxFailures
+}
+
+// Polls a thread and reports changes to it.
+type ThreadWatcher struct {
+	Board string
+	// The post number of the OP.
+	ID ThreadID
+	// Time between polls, defaults to DefaultWatchInterval.
+	Interval time.Duration
+	// Check the thread's last_modified in threads.json before loading the thread itself.
+	// This saves transfers when watching several threads on one board, at the cost of a request per poll.
+	UseThreadList bool
+	// The client to poll with, defaults to DefaultClient.
+	Client *Client
+	// What to do when polls fail, nil keeps polling at Interval forever.
+	Retry *RetryPolicy
+	// Only remember the OP and this many of the latest replies, zero remembers the whole thread.
+	// This bounds the memory a watcher holds on to for huge threads, but deletions of replies it
+	// no longer remembers go unreported. Event snapshots still hold the whole thread.
+	KeepReplies int
+	// The last known state of the thread, set it to resume watching without reporting old posts again.
+	// It is read once when Watch starts, use Latest for what the watcher has seen since.
+	Thread *Thread
+
+	mu     sync.Mutex
+	latest *Thread
+}
+
+// Start polling in the background.
+// The returned channel is closed once ctx is done, the thread is archived or gone, or the watcher gives up.
+// Watching an immutable Thread is refused, the only event is a WatchError with ImmutableThreadError.
+func (w *ThreadWatcher) Watch(ctx context.Context) <-chan WatchEvent {
+	w.setLatest(w.trim(w.Thread))
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		if w.Thread != nil && w.Thread.IsImmutable() {
+			err := ImmutableThreadError{w.Board, strconv.FormatUint(w.ID, 10)}
+			select {
+			case events <- w.event(WatchError, nil, nil, err):
+			case <-ctx.Done():
+			}
+			return
+		}
+		w.run(ctx, events)
+	}()
+	return events
+}
+
+func (w *ThreadWatcher) run(ctx context.Context, events chan<- WatchEvent) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	client := w.Client
+	if client == nil {
+		client = defaultClient()
+	}
+
+	failures := 0
+	for {
+		changes := w.poll(ctx, client)
+		if len(changes) == 1 && changes[0].Kind == WatchError {
+			failures++
+		} else {
+			failures = 0
+		}
+		if w.Retry.exhausted(failures) {
+			changes = append(changes, w.event(WatchGaveUp, nil, nil, changes[0].Err))
+		}
+
+		delay := w.Retry.delay(failures, interval)
+		for _, event := range changes {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+			if event.Kind == WatchArchived || event.Kind == WatchNotFound || event.Kind == WatchGaveUp {
+				return
+			}
+			// Back off for as long as the server asks.
+			if limited, ok := event.Err.(RateLimitedError); ok && limited.RetryAfter > delay {
+				delay = limited.RetryAfter
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// The thread as of the last poll that changed it. Until then it's the Thread the watcher was
+// started with, nil if there was none, and it is nil before Watch is called.
+// With KeepReplies set it only has the OP and the latest replies.
+// It is safe to call while the watcher runs, and the snapshot returned is never changed.
+func (w *ThreadWatcher) Latest() *Thread {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.latest
+}
+
+func (w *ThreadWatcher) setLatest(t *Thread) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.latest = t
+}
+
+// Check the thread once and report what changed.
+func (w *ThreadWatcher) poll(ctx context.Context, client *Client) []WatchEvent {
+	known := w.Latest()
+	id := strconv.FormatUint(w.ID, 10)
+
+	if w.UseThreadList && known != nil && len(known.Posts) > 0 {
+		list, err := client.LoadThreadList(ctx, w.Board)
+		if err != nil {
+			return []WatchEvent{w.event(WatchError, nil, nil, err)}
+		}
+		for _, page := range list.Pages {
+			for _, summary := range page.Threads {
+				if summary.PostNumber == w.ID && summary.LastModified <= known.lastModified() {
+					return nil
+				}
+			}
+		}
+		// Changed or no longer listed, the thread itself says which.
+	}
+
+	var since time.Time
+	if known != nil {
+		since = known.ModifiedAt
+	}
+	thread, err := client.loadThreadIfModified(ctx, w.Board, id, since)
+	if _, gone := err.(ThreadNotFoundError); gone {
+		return []WatchEvent{w.event(WatchNotFound, nil, nil, err)}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return []WatchEvent{w.event(WatchError, nil, nil, err)}
+	}
+	if thread == nil {
+		return nil
+	}
+	w.setLatest(w.trim(thread))
+
+	var changes []WatchEvent
+	diff := thread.Diff(known)
+	if w.KeepReplies > 0 && known != nil && len(known.Posts) > 1 {
+		// Replies older than the ones remembered aren't new, they were forgotten.
+		oldest := known.Posts[1].PostNumber
+		added := diff.Added[:0:0]
+		for _, post := range diff.Added {
+			if post.PostNumber > oldest {
+				added = append(added, post)
+			}
+		}
+		diff.Added = added
+	}
+	if len(diff.Added) > 0 {
+		changes = append(changes, w.event(WatchNewPosts, diff.Added, thread, nil))
+	}
+	if len(diff.Removed) > 0 {
+		changes = append(changes, w.event(WatchDeletedPosts, diff.Removed, thread, nil))
+	}
+	if len(diff.RolledOff) > 0 {
+		changes = append(changes, w.event(WatchRolledOff, diff.RolledOff, thread, nil))
+	}
+	if len(thread.Posts) > 0 && thread.Posts[0].Archived {
+		changes = append(changes, w.event(WatchArchived, nil, thread, nil))
+	}
+
+	return changes
+}
+
+// The part of the thread the watcher remembers, see KeepReplies.
+func (w *ThreadWatcher) trim(t *Thread) *Thread {
+	if t == nil || w.KeepReplies <= 0 || len(t.Posts) <= w.KeepReplies+1 {
+		return t
+	}
+	trimmed := *t
+	// Copy the posts, so the full thread isn't kept alive by the slice.
+	trimmed.Posts = append([]Post{t.Posts[0]}, t.Posts[len(t.Posts)-w.KeepReplies:]...)
+	return &trimmed
+}
+
+func (w *ThreadWatcher) event(kind WatchEventKind, posts []Post, snapshot *Thread, err error) WatchEvent {
+	return WatchEvent{Kind: kind, Board: w.Board, Thread: w.ID, Posts: posts, Snapshot: snapshot, Err: err}
+}
+
+// Load a thread unless it hasn't changed since the given time, a zero time always loads it.
+// Returns a nil thread when it hasn't changed, and ThreadNotFoundError when the thread is gone.
+func (c *Client) loadThreadIfModified(ctx context.Context, board, id string, since time.Time) (*Thread, error) {
+	url := fmt.Sprintf("%s/%s/thread/%s.json", c.apiURL, board, id)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !since.IsZero() {
+		req.Header.Set("If-Modified-Since", since.UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, nil
+	case http.StatusNotFound:
+		return nil, ThreadNotFoundError{board, id}
+	case http.StatusOK:
+	default:
+		return nil, responseError(url, resp)
+	}
+
+	thread := &Thread{}
+	if err = c.decodeBody(url, resp, c.threadTarget(thread)); err != nil {
+		return nil, err
+	}
+	stampThread(thread, board, resp.Header)
+
+	return thread, nil
+}
@@ -0,0 +1,265 @@
+package fourchan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Serves one thread whose contents the test can change, answering If-Modified-Since.
+type watchedThread struct {
+	mu       sync.Mutex
+	body     string
+	modified time.Time
+}
+
+func (s *watchedThread) set(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+	s.modified = s.modified.Add(time.Minute)
+}
+
+func (s *watchedThread) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.body == "" || r.URL.Path != "/g/thread/1.json" {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeContent(w, r, "1.json", s.modified, strings.NewReader(s.body))
+}
+
+func nextEvent(t *testing.T, events <-chan WatchEvent) WatchEvent {
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("events closed")
+		}
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	return WatchEvent{}
+}
+
+func TestThreadWatcher(t *testing.T) {
+	thread := &watchedThread{modified: time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)}
+	thread.set(`{"posts": [{"no": 1}, {"no": 2}]}`)
+	server := httptest.NewServer(thread)
+	defer server.Close()
+
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, Client: newTestClient(server)}
+	events := w.Watch(context.Background())
+
+	// Reading the latest snapshot while polling doesn't race.
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			if latest := w.Latest(); latest != nil && len(latest.Posts) == 0 {
+				t.Error("empty snapshot")
+			}
+		}
+		close(done)
+	}()
+	defer func() { <-done }()
+
+	event := nextEvent(t, events)
+	if event.Kind != WatchNewPosts || len(event.Posts) != 2 || event.Snapshot == nil || event.Snapshot.Board != "g" {
+		t.Fatalf("bad first event %+v", event)
+	}
+
+	thread.set(`{"posts": [{"no": 1}, {"no": 3}]}`)
+	event = nextEvent(t, events)
+	if event.Kind != WatchNewPosts || len(event.Posts) != 1 || event.Posts[0].PostNumber != 3 {
+		t.Fatalf("bad new posts event %+v", event)
+	}
+	event = nextEvent(t, events)
+	if event.Kind != WatchDeletedPosts || len(event.Posts) != 1 || event.Posts[0].PostNumber != 2 {
+		t.Fatalf("bad deleted posts event %+v", event)
+	}
+
+	thread.set(`{"posts": [{"no": 1, "archived": 1}, {"no": 3}]}`)
+	event = nextEvent(t, events)
+	if event.Kind != WatchArchived {
+		t.Fatalf("bad archived event %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events not closed after archiving")
+	}
+	if !w.Latest().Posts[0].Archived {
+		t.Fatal("last known thread not kept")
+	}
+}
+
+func TestThreadWatcherNotFound(t *testing.T) {
+	server := httptest.NewServer(&watchedThread{})
+	defer server.Close()
+
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, Client: newTestClient(server)}
+	events := w.Watch(context.Background())
+	if event := nextEvent(t, events); event.Kind != WatchNotFound {
+		t.Fatalf("bad event %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events not closed after 404")
+	}
+}
+
+func TestThreadWatcherImmutable(t *testing.T) {
+	server := httptest.NewServer(&watchedThread{})
+	defer server.Close()
+
+	known := &Thread{Board: "g", Posts: []Post{{Meta: Meta{PostNumber: 1, Archived: true}}}}
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, Client: newTestClient(server), Thread: known}
+	events := w.Watch(context.Background())
+	if event := nextEvent(t, events); event.Kind != WatchError || event.Err != (ImmutableThreadError{"g", "1"}) {
+		t.Fatalf("bad event %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events not closed for an archived thread")
+	}
+}
+
+func TestThreadWatcherUseThreadList(t *testing.T) {
+	thread := &watchedThread{modified: time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)}
+	thread.set(`{"posts": [{"no": 1}]}`)
+	var mu sync.Mutex
+	loads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/g/threads.json" {
+			thread.mu.Lock()
+			defer thread.mu.Unlock()
+			fmt.Fprintf(w, `[{"page": 1, "threads": [{"no": 1, "last_modified": %d}]}]`, thread.modified.Unix())
+			return
+		}
+		mu.Lock()
+		loads++
+		mu.Unlock()
+		thread.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, UseThreadList: true, Client: newTestClient(server)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := w.Watch(ctx)
+	if event := nextEvent(t, events); event.Kind != WatchNewPosts {
+		t.Fatalf("bad first event %+v", event)
+	}
+
+	// Unchanged in threads.json, so the thread itself isn't loaded again.
+	time.Sleep(30 * time.Millisecond)
+	mu.Lock()
+	if loads != 1 {
+		t.Fatalf("thread loaded %d times", loads)
+	}
+	mu.Unlock()
+
+	thread.set(`{"posts": [{"no": 1}, {"no": 2}]}`)
+	if event := nextEvent(t, events); event.Kind != WatchNewPosts || len(event.Posts) != 1 || event.Posts[0].PostNumber != 2 {
+		t.Fatalf("bad new posts event %+v", event)
+	}
+}
+
+func TestThreadWatcherCancel(t *testing.T) {
+	thread := &watchedThread{}
+	thread.set(`{"posts": [{"no": 1}]}`)
+	server := httptest.NewServer(thread)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	known, _ := newTestClient(server).LoadThreadById(ctx, "g", "1")
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, Client: newTestClient(server), Thread: known}
+	events := w.Watch(ctx)
+
+	// Nothing changed, so nothing is reported until cancelled.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case event, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("events not closed after cancel")
+	}
+}
+
+func TestWatchEventKindString(t *testing.T) {
+	if WatchDeletedPosts.String() != "deleted posts" || WatchRolledOff.String() != "rolled off" || WatchEventKind(99).String() != "WatchEventKind(99)" {
+		t.Fatal("bad names")
+	}
+}
+
+func TestThreadWatcherGiveUp(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: time.Hour, Client: newTestClient(server),
+		Retry: &RetryPolicy{Backoff: time.Millisecond, MaxFailures: 3}}
+	events := w.Watch(context.Background())
+	for i := 0; i < 3; i++ {
+		if event := nextEvent(t, events); event.Kind != WatchError {
+			t.Fatalf("bad event %+v", event)
+		}
+	}
+	if event := nextEvent(t, events); event.Kind != WatchGaveUp || event.Err.(ServerError).StatusCode != http.StatusBadGateway {
+		t.Fatalf("bad event %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("events not closed after giving up")
+	}
+	if polls != 3 {
+		t.Fatalf("%d polls", polls)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	var none *RetryPolicy
+	if d := none.delay(5, time.Second); d != time.Second || none.exhausted(100) {
+		t.Fatal("nil policy backs off")
+	}
+	p := &RetryPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for failures, want := range []time.Duration{time.Minute, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if d := p.delay(failures, time.Minute); d != want {
+			t.Fatalf("%d failures: %v, want %v", failures, d, want)
+		}
+	}
+}
+
+func TestThreadWatcherKeepReplies(t *testing.T) {
+	thread := &watchedThread{}
+	thread.set(`{"posts": [{"no": 1}, {"no": 2}, {"no": 3}, {"no": 4}]}`)
+	server := httptest.NewServer(thread)
+	defer server.Close()
+
+	w := &ThreadWatcher{Board: "g", ID: 1, Interval: 5 * time.Millisecond, Client: newTestClient(server), KeepReplies: 2}
+	events := w.Watch(context.Background())
+	if event := nextEvent(t, events); event.Kind != WatchNewPosts || len(event.Posts) != 4 || len(event.Snapshot.Posts) != 4 {
+		t.Fatalf("bad first event %+v", event)
+	}
+	if latest := w.Latest(); len(latest.Posts) != 3 || latest.Posts[0].PostNumber != 1 || latest.Posts[1].PostNumber != 3 {
+		t.Fatalf("bad latest %+v", latest)
+	}
+
+	// Forgotten replies aren't reported again, deleting a remembered one is.
+	thread.set(`{"posts": [{"no": 1}, {"no": 2}, {"no": 4}, {"no": 5}]}`)
+	event := nextEvent(t, events)
+	if event.Kind != WatchNewPosts || len(event.Posts) != 1 || event.Posts[0].PostNumber != 5 {
+		t.Fatalf("bad new posts event %+v", event)
+	}
+	event = nextEvent(t, events)
+	if event.Kind != WatchDeletedPosts || len(event.Posts) != 1 || event.Posts[0].PostNumber != 3 {
+		t.Fatalf("bad deleted posts event %+v", event)
+	}
+}